@@ -0,0 +1,71 @@
+package godotenv
+
+import "log"
+
+type alias struct {
+	name            string
+	deprecatedNames []string
+}
+
+// Alias specifies deprecated names of a variable. If a source doesn't set the variable, but sets one of the deprecated
+// names, the value of the deprecated name is used for the variable. Deprecated names are checked in the given order.
+//
+// Aliases are resolved within each source, so the usual precedence of sources holds: a deprecated name set in a dotenv
+// file wins over the new name set in the system environment, unless PrioritizeSystem is used.
+// Each use of a deprecated name is reported with the callback given in the OnDeprecated option.
+//
+// Names are not relative to the prefix given in the WithPrefix option.
+func Alias(name string, deprecatedNames ...string) Option {
+	return func(cfg *config) {
+		cfg.aliases = append(cfg.aliases, alias{name: name, deprecatedNames: deprecatedNames})
+	}
+}
+
+// OnDeprecated specifies the callback that is called when a value of a variable is taken from its deprecated name.
+//
+// By default, a warning is written with the standard logger.
+func OnDeprecated(warn func(deprecatedName, name string)) Option {
+	return func(cfg *config) {
+		cfg.deprecated = warn
+	}
+}
+
+func logDeprecated(deprecatedName, name string) {
+	log.Printf("godotenv: %s is deprecated, use %s instead", deprecatedName, name)
+}
+
+// applyAliases sets variables of l that are not set from their deprecated names.
+func (cfg config) applyAliases(l *layer) {
+	for _, a := range cfg.aliases {
+		if _, ok := l.get(a.name); ok {
+			continue
+		}
+
+		for _, deprecatedName := range a.deprecatedNames {
+			if value, ok := l.get(deprecatedName); ok {
+				l.variables[a.name] = value
+				if l.aliased == nil {
+					l.aliased = make(map[string]string)
+				}
+				l.aliased[a.name] = deprecatedName
+				break
+			}
+		}
+	}
+}
+
+// warnDeprecated reports the deprecated name, if lookup takes the value of the variable from it.
+func (cfg config) warnDeprecated(layers []layer, variable string, emptyIsUnset bool) {
+	l := origin(layers, variable, emptyIsUnset)
+	if l == nil {
+		return
+	}
+
+	if deprecatedName, ok := l.aliased[variable]; ok {
+		warn := cfg.deprecated
+		if warn == nil {
+			warn = logDeprecated
+		}
+		warn(deprecatedName, variable)
+	}
+}
@@ -0,0 +1,56 @@
+package godotenv
+
+import (
+	"os"
+	"testing"
+)
+
+func TestAlias(t *testing.T) {
+	var warnings []string
+	warn := OnDeprecated(func(deprecatedName, name string) {
+		warnings = append(warnings, deprecatedName+"->"+name)
+	})
+
+	envMap, notFoundVars := Get(Variables("DATABASE_URL"), From("fixtures/deprecated.env"), Alias("DATABASE_URL", "DB_URL"), warn)
+	if len(notFoundVars) != 0 || envMap["DATABASE_URL"] != "postgres://deprecated" {
+		t.Errorf("Expected DATABASE_URL to fall back to DB_URL, got %+v.", envMap)
+	}
+	if len(warnings) != 1 || warnings[0] != "DB_URL->DATABASE_URL" {
+		t.Errorf("Expected one warning about DB_URL, got %+v.", warnings)
+	}
+
+	warnings = nil
+	envMap, _ = Get(From("fixtures/deprecated.env"), Alias("DATABASE_URL", "DB_URL"), warn)
+	if envMap["DATABASE_URL"] != "postgres://deprecated" || envMap["DB_URL"] != "postgres://deprecated" {
+		t.Errorf("Expected DATABASE_URL to be added to all variables, got %+v.", envMap)
+	}
+	if len(warnings) != 1 {
+		t.Errorf("Expected one warning about DB_URL, got %+v.", warnings)
+	}
+}
+
+func TestAliasPrecedence(t *testing.T) {
+	err := os.Setenv("DATABASE_URL", "postgres://system")
+	if err != nil {
+		t.Fatalf("Error setting environment variable: %v.", err)
+	}
+	defer os.Unsetenv("DATABASE_URL")
+
+	warnings := 0
+	warn := OnDeprecated(func(string, string) { warnings++ })
+
+	envMap, _ := Get(Variables("DATABASE_URL"), From("fixtures/deprecated.env"), Alias("DATABASE_URL", "DB_URL"), warn)
+	if envMap["DATABASE_URL"] != "postgres://deprecated" || warnings != 1 {
+		t.Errorf("Expected deprecated name from the dotenv file to win, got %+v.", envMap)
+	}
+
+	envMap, _ = Get(Variables("DATABASE_URL"), From("fixtures/deprecated.env"), Alias("DATABASE_URL", "DB_URL"), warn, PrioritizeSystem())
+	if envMap["DATABASE_URL"] != "postgres://system" || warnings != 1 {
+		t.Errorf("Expected the system variable to win, got %+v.", envMap)
+	}
+
+	envMap, _ = Get(Variables("DATABASE_URL"), From("fixtures/deprecated.env"), Alias("DATABASE_URL", "NOT_SET", "DB_URL"), warn)
+	if envMap["DATABASE_URL"] != "postgres://deprecated" || warnings != 2 {
+		t.Errorf("Expected the second deprecated name to be used, got %+v.", envMap)
+	}
+}
@@ -0,0 +1,336 @@
+package godotenv
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// parseCompose parses src the way Docker Compose reads env_file files.
+//
+// A key without a value is inherited from the environment; if it is not set there, the key is skipped.
+func parseCompose(src string, lookup func(string) (string, bool)) (map[string]string, error) {
+	p := composeParser{line: 1, lookup: lookup}
+	envMap := make(map[string]string)
+
+	for rest := p.statementStart(src); rest != ""; rest = p.statementStart(rest) {
+		key, left, inherited, err := p.keyName(rest)
+		if err != nil {
+			return envMap, err
+		}
+		if strings.Contains(key, " ") {
+			return envMap, fmt.Errorf("line %d: key cannot contain a space", p.line)
+		}
+
+		if inherited {
+			if value, ok := lookup(key); ok {
+				envMap[key] = value
+			}
+			rest = left
+			continue
+		}
+
+		value, left, err := p.value(left, envMap)
+		if err != nil {
+			return envMap, err
+		}
+		envMap[key] = value
+		rest = left
+	}
+
+	return envMap, nil
+}
+
+type composeParser struct {
+	line   int
+	lookup func(string) (string, bool)
+}
+
+// statementStart skips whitespace and comments before the next statement.
+func (p *composeParser) statementStart(src string) string {
+	for {
+		pos := strings.IndexFunc(src, func(r rune) bool {
+			if r == '\n' {
+				p.line++
+			}
+			return !unicode.IsSpace(r)
+		})
+		if pos == -1 {
+			return ""
+		}
+
+		src = src[pos:]
+		if src[0] != '#' {
+			return src
+		}
+
+		pos = strings.IndexByte(src, '\n')
+		if pos == -1 {
+			return ""
+		}
+		src = src[pos:]
+	}
+}
+
+// keyName reads the key of a statement.
+// A key followed by a line break (or the end of input) instead of a value is inherited.
+func (p *composeParser) keyName(src string) (key, rest string, inherited bool, err error) {
+	if after := strings.TrimPrefix(src, "export"); after != src && after != "" && isComposeSpace(rune(after[0])) {
+		src = strings.TrimLeftFunc(after, isComposeSpace)
+	}
+
+	key, offset, inherited := src, len(src), true
+
+loop:
+	for i, r := range src {
+		if isComposeSpace(r) {
+			continue
+		}
+		switch r {
+		case '=', ':', '\n':
+			key = src[:i]
+			offset = i + 1
+			inherited = r == '\n'
+			if inherited {
+				p.line++
+			}
+			break loop
+		case '_', '.', '-', '[', ']':
+		default:
+			if unicode.IsLetter(r) || unicode.IsNumber(r) {
+				continue
+			}
+			return "", "", false, fmt.Errorf("line %d: unexpected character %q in variable name %q", p.line, string(r), strings.SplitN(src, "\n", 2)[0])
+		}
+	}
+
+	key = strings.TrimRightFunc(key, unicode.IsSpace)
+	return key, strings.TrimLeftFunc(src[offset:], isComposeSpace), inherited, nil
+}
+
+func (p *composeParser) value(src string, envMap map[string]string) (value, rest string, err error) {
+	if src == "" || src[0] != '"' && src[0] != '\'' {
+		value, rest = src, ""
+		if i := strings.IndexByte(src, '\n'); i >= 0 {
+			value, rest = src[:i], src[i+1:]
+		}
+		p.line++
+
+		if i := strings.Index(value, " #"); i >= 0 {
+			value = value[:i]
+		}
+		value = strings.TrimRightFunc(value, unicode.IsSpace)
+
+		value, err = p.expand(value, envMap)
+		return value, rest, err
+	}
+
+	quote := src[0]
+	escaped := false
+	var chars []byte
+	for i := 1; i < len(src); i++ {
+		c := src[i]
+		if c == '\n' {
+			p.line++
+		}
+
+		if c != quote {
+			if !escaped && c == '\\' {
+				escaped = true
+				continue
+			}
+			if escaped {
+				escaped = false
+				chars = append(chars, '\\')
+			}
+			chars = append(chars, c)
+			continue
+		}
+
+		if escaped {
+			escaped = false
+			chars = append(chars, c)
+			continue
+		}
+
+		value = string(chars)
+		if quote == '"' {
+			value, err = p.expand(expandComposeEscapes(value), envMap)
+			if err != nil {
+				return "", "", err
+			}
+		}
+		return value, src[i+1:], nil
+	}
+
+	end := strings.IndexByte(src, '\n')
+	if end == -1 {
+		end = len(src)
+	}
+	return "", "", fmt.Errorf("line %d: unterminated quoted value %s", p.line, src[:end])
+}
+
+func isComposeSpace(r rune) bool {
+	switch r {
+	case '\t', '\v', '\f', '\r', ' ', 0x85, 0xA0:
+		return true
+	}
+	return false
+}
+
+// expandComposeEscapes replaces the escape sequences supported by Compose in double-quoted values.
+// An escaped dollar sign becomes "$$", so it is not expanded.
+func expandComposeEscapes(value string) string {
+	var sb strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 == len(value) {
+			sb.WriteByte(value[i])
+			continue
+		}
+
+		i++
+		switch c := value[i]; c {
+		case 'a', 'b', 'f', 'n', 'r', 't', 'v', '"', '\\':
+			unquoted, _ := strconv.Unquote(`"\` + string(c) + `"`)
+			sb.WriteString(unquoted)
+		case '$':
+			sb.WriteString("$$")
+		default:
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		}
+	}
+	return sb.String()
+}
+
+// expand substitutes variables the way Compose does, looking them up in envMap first and in the environment then.
+func (p *composeParser) expand(value string, envMap map[string]string) (string, error) {
+	lookup := func(name string) (string, bool) {
+		if v, ok := envMap[name]; ok {
+			return v, true
+		}
+		return p.lookup(name)
+	}
+
+	result, err := substituteCompose(value, lookup)
+	if err != nil {
+		return "", fmt.Errorf("line %d: %w", p.line, err)
+	}
+	return result, nil
+}
+
+func substituteCompose(value string, lookup func(string) (string, bool)) (string, error) {
+	var sb strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		if value[i] != '$' {
+			sb.WriteByte(value[i])
+			continue
+		}
+
+		rest := value[i+1:]
+		switch {
+		case strings.HasPrefix(rest, "$"):
+			sb.WriteByte('$')
+			i++
+		case strings.HasPrefix(rest, "{"):
+			end := closingBrace(rest)
+			if end < 0 {
+				return "", fmt.Errorf("invalid template: %q", value)
+			}
+			substituted, err := substituteComposeBraced(rest[1:end], lookup)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(substituted)
+			i += end + 1
+		default:
+			name := composeName(rest)
+			if name == "" {
+				return "", fmt.Errorf("invalid template: %q", value)
+			}
+			v, _ := lookup(name)
+			sb.WriteString(v)
+			i += len(name)
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// closingBrace returns the index of the brace closing the one at the start of s, respecting nested templates.
+func closingBrace(s string) int {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func composeName(s string) string {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9' {
+			continue
+		}
+		return s[:i]
+	}
+	return s
+}
+
+// substituteComposeBraced substitutes the content of ${...}: a name optionally followed by a modifier.
+func substituteComposeBraced(template string, lookup func(string) (string, bool)) (string, error) {
+	name := composeName(template)
+	if name == "" {
+		return "", fmt.Errorf("invalid template: %q", "${"+template+"}")
+	}
+
+	modifier := template[len(name):]
+	value, ok := lookup(name)
+	if modifier == "" {
+		return value, nil
+	}
+
+	colon := strings.HasPrefix(modifier, ":")
+	modifier = strings.TrimPrefix(modifier, ":")
+	if modifier == "" {
+		return "", fmt.Errorf("invalid template: %q", "${"+template+"}")
+	}
+
+	// With a colon, an empty value is treated the same as an unset one.
+	set := ok && (!colon || value != "")
+	argument, err := substituteCompose(modifier[1:], lookup)
+	if err != nil {
+		return "", err
+	}
+
+	switch modifier[0] {
+	case '-':
+		if set {
+			return value, nil
+		}
+		return argument, nil
+	case '+':
+		if set {
+			return argument, nil
+		}
+		return "", nil
+	case '?':
+		if set {
+			return value, nil
+		}
+		return "", errors.New("required variable " + name + " is missing a value: " + argument)
+	default:
+		return "", fmt.Errorf("invalid template: %q", "${"+template+"}")
+	}
+}
@@ -0,0 +1,63 @@
+package godotenv
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+)
+
+// conformanceCorpus holds inputs of the default dialect with the variables they define, or the line of the error.
+// It is versioned, so other implementations can use it too.
+type conformanceCorpus struct {
+	Version     int    `json:"version"`
+	Description string `json:"description"`
+	Cases       []struct {
+		Name     string            `json:"name"`
+		Input    string            `json:"input"`
+		Expected map[string]string `json:"expected"`
+		Error    *struct {
+			Line int `json:"line"`
+		} `json:"error"`
+	} `json:"cases"`
+}
+
+func readConformanceCorpus(t testing.TB) conformanceCorpus {
+	data, err := os.ReadFile("fixtures/conformance.json")
+	if err != nil {
+		t.Fatalf("Error reading corpus: %v.", err)
+	}
+	var corpus conformanceCorpus
+	if err := json.Unmarshal(data, &corpus); err != nil {
+		t.Fatalf("Error decoding corpus: %v.", err)
+	}
+	return corpus
+}
+
+func TestConformance(t *testing.T) {
+	corpus := readConformanceCorpus(t)
+	if corpus.Version != 1 {
+		t.Fatalf("Unknown corpus version %d.", corpus.Version)
+	}
+
+	for _, tt := range corpus.Cases {
+		t.Run(tt.Name, func(t *testing.T) {
+			if (tt.Expected == nil) == (tt.Error == nil) {
+				t.Fatal("Case must have either expected variables or an error.")
+			}
+
+			envMap, err := ParseString(tt.Input)
+			if tt.Error != nil {
+				var parseErr *ParseError
+				if !errors.As(err, &parseErr) || parseErr.Line != tt.Error.Line {
+					t.Errorf("Expected an error on line %d, got %v and %+v.", tt.Error.Line, err, envMap)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Error: %v.", err)
+			}
+			compareEnvMaps(t, tt.Expected, envMap)
+		})
+	}
+}
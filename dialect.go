@@ -0,0 +1,286 @@
+package godotenv
+
+import (
+	"io"
+	"regexp"
+	"strings"
+)
+
+// DialectKind identifies the dotenv syntax rules of a particular tool.
+type DialectKind int
+
+const (
+	// DefaultDialect is the syntax of this library.
+	DefaultDialect DialectKind = iota
+	// ComposeDialect reproduces the rules of env_file files of Docker Compose.
+	ComposeDialect
+	// SystemdDialect reproduces the rules of systemd's EnvironmentFile= files.
+	SystemdDialect
+	// NodeDialect reproduces the rules of the dotenv package for Node.js.
+	NodeDialect
+	// PythonDialect reproduces the rules of the python-dotenv package.
+	PythonDialect
+	// ShellDialect reproduces the rules of a POSIX shell sourcing the file with "set -a; . ./.env".
+	ShellDialect
+)
+
+var dialectNames = map[DialectKind]string{
+	DefaultDialect: "default",
+	ComposeDialect: "compose",
+	SystemdDialect: "systemd",
+	NodeDialect:    "node",
+	PythonDialect:  "python",
+	ShellDialect:   "shell",
+}
+
+func (d DialectKind) String() string {
+	if name, ok := dialectNames[d]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Dialect specifies the syntax rules used to parse dotenv sources, so the variables are read exactly like
+// another tool reads the same file. The dialect does not affect sources in other formats.
+//
+// The rules differ in quoting, escaping, comments and variable expansion:
+//
+//		ComposeDialect: shell-like escapes in double quotes, ${VAR:-default}-style expansion, "#" starts a comment after a space.
+//		SystemdDialect: no expansion, "#" and ";" start comments only at the beginning of a line, lines can be continued with "\".
+//		NodeDialect: no expansion, only \n and \r escapes in double quotes, backtick quotes, invalid lines are skipped.
+//		PythonDialect: only ${VAR} and ${VAR:-default} are expanded, "#" starts a comment after a space, invalid lines are skipped.
+//		ShellDialect: shell quoting and parameter expansion; anything but assignments and export is an error.
+//
+// Compose, python-dotenv and the shell look up undefined variables in the system environment, and so do these dialects,
+// using the environment given with WithEnviron or WithLookup options, if any.
+func Dialect(dialect DialectKind) Option {
+	return func(cfg *config) {
+		cfg.parse.dialect = dialect
+	}
+}
+
+// CommentsAfterWhitespace makes a hash start an inline comment only if it follows a space or a tab, like in Compose and
+// the shell, so URL=https://example.com/#fragment keeps the fragment. By default, a hash outside quotes starts a comment
+// anywhere on the line. It affects the default dialect only.
+func CommentsAfterWhitespace() Option {
+	return func(cfg *config) {
+		cfg.parse.commentsAfterWhitespace = true
+	}
+}
+
+// parseDialect parses dotenv content of r according to the rules of the dialect of p.
+// Undefined variables are looked up in the environment of p by dialects that expand them.
+func parseDialect(r io.Reader, p parseConfig) (map[string]string, error) {
+	dialect, lookup := p.dialect, p.env.lookup
+	if dialect == DefaultDialect {
+		// The size of the content is limited by the caller.
+		p.maxFileSize = 0
+		return scanAll(newScanner(r, p))
+	}
+
+	data, err := io.ReadAll(newDecoder(r))
+	if err != nil {
+		return nil, err
+	}
+	src := string(data)
+
+	switch dialect {
+	case ComposeDialect:
+		return parseCompose(strings.ReplaceAll(src, "\r\n", "\n"), lookup)
+	case SystemdDialect:
+		return parseSystemd(src), nil
+	case NodeDialect:
+		return parseNode(src), nil
+	case PythonDialect:
+		return parsePython(src, lookup), nil
+	case ShellDialect:
+		return parseShell(src, lookup)
+	default:
+		return parse(strings.NewReader(src))
+	}
+}
+
+var nodeLineRegex = regexp.MustCompile(`(?m)^\s*(?:export\s+)?([\w.-]+)(?:\s*=\s*?|:\s+?)(\s*'(?:\\'|[^'])*'|\s*"(?:\\"|[^"])*"|\s*` + "`(?:\\\\`|[^`])*`" + `|[^#\r\n]+)?\s*(?:#.*)?$`)
+
+// parseNode parses src the way the dotenv package for Node.js does. Lines that don't match are skipped.
+func parseNode(src string) map[string]string {
+	envMap := make(map[string]string)
+
+	src = strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(src)
+	for _, match := range nodeLineRegex.FindAllStringSubmatch(src, -1) {
+		value := strings.TrimSpace(match[2])
+
+		quote := byte(0)
+		if len(value) > 1 && strings.IndexByte("'\"`", value[0]) >= 0 && value[len(value)-1] == value[0] {
+			quote = value[0]
+			value = value[1 : len(value)-1]
+		}
+		if quote == '"' {
+			value = strings.NewReplacer(`\n`, "\n", `\r`, "\r").Replace(value)
+		}
+
+		envMap[match[1]] = value
+	}
+
+	return envMap
+}
+
+type systemdState int
+
+const (
+	systemdPreKey systemdState = iota
+	systemdKey
+	systemdPreValue
+	systemdValue
+	systemdValueEscape
+	systemdSingleQuoteValue
+	systemdDoubleQuoteValue
+	systemdDoubleQuoteValueEscape
+	systemdComment
+)
+
+// parseSystemd parses src the way systemd reads EnvironmentFile= files.
+// Lines without "=" and assignments with invalid variable names are skipped.
+func parseSystemd(src string) map[string]string {
+	envMap := make(map[string]string)
+
+	state := systemdPreKey
+	var key, value strings.Builder
+	// Trailing whitespace of keys and unquoted values is not a part of them.
+	keyEnd, valueEnd := -1, -1
+
+	push := func() {
+		k, v := key.String(), value.String()
+		if keyEnd >= 0 {
+			k = k[:keyEnd]
+		}
+		if valueEnd >= 0 {
+			v = v[:valueEnd]
+		}
+		if isSystemdName(k) {
+			envMap[k] = v
+		}
+		key.Reset()
+		value.Reset()
+		keyEnd, valueEnd = -1, -1
+	}
+
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		newline := c == '\n' || c == '\r'
+		whitespace := c == ' ' || c == '\t' || newline
+
+		switch state {
+		case systemdPreKey:
+			switch {
+			case c == '#' || c == ';':
+				state = systemdComment
+			case !whitespace:
+				state = systemdKey
+				key.WriteByte(c)
+			}
+		case systemdKey:
+			switch {
+			case newline:
+				state = systemdPreKey
+				key.Reset()
+				keyEnd = -1
+			case c == '=':
+				state = systemdPreValue
+			default:
+				if !whitespace {
+					keyEnd = -1
+				} else if keyEnd < 0 {
+					keyEnd = key.Len()
+				}
+				key.WriteByte(c)
+			}
+		case systemdPreValue:
+			switch {
+			case newline:
+				state = systemdPreKey
+				push()
+			case c == '\'':
+				state = systemdSingleQuoteValue
+			case c == '"':
+				state = systemdDoubleQuoteValue
+			case c == '\\':
+				state = systemdValueEscape
+			case !whitespace:
+				state = systemdValue
+				value.WriteByte(c)
+			}
+		case systemdValue:
+			switch {
+			case newline:
+				state = systemdPreKey
+				push()
+			case c == '\\':
+				state = systemdValueEscape
+				valueEnd = -1
+			default:
+				if !whitespace {
+					valueEnd = -1
+				} else if valueEnd < 0 {
+					valueEnd = value.Len()
+				}
+				value.WriteByte(c)
+			}
+		case systemdValueEscape:
+			state = systemdValue
+			if !newline {
+				value.WriteByte(c)
+			}
+		case systemdSingleQuoteValue:
+			if c == '\'' {
+				state = systemdPreValue
+			} else {
+				value.WriteByte(c)
+			}
+		case systemdDoubleQuoteValue:
+			switch c {
+			case '"':
+				state = systemdPreValue
+			case '\\':
+				state = systemdDoubleQuoteValueEscape
+			default:
+				value.WriteByte(c)
+			}
+		case systemdDoubleQuoteValueEscape:
+			state = systemdDoubleQuoteValue
+			switch {
+			case strings.IndexByte("\"\\`$", c) >= 0:
+				value.WriteByte(c)
+			case !newline:
+				value.WriteByte('\\')
+				value.WriteByte(c)
+			}
+		case systemdComment:
+			if newline {
+				state = systemdPreKey
+			}
+		}
+	}
+
+	switch state {
+	case systemdPreValue, systemdValue, systemdValueEscape, systemdSingleQuoteValue,
+		systemdDoubleQuoteValue, systemdDoubleQuoteValueEscape:
+		push()
+	}
+
+	return envMap
+}
+
+// isSystemdName reports whether the name is a valid variable name for systemd.
+func isSystemdName(name string) bool {
+	if name == "" || name[0] >= '0' && name[0] <= '9' {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if c != '_' && (c < 'a' || c > 'z') && (c < 'A' || c > 'Z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return true
+}
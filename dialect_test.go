@@ -0,0 +1,96 @@
+package godotenv
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+type dialectCorpus struct {
+	Version int `json:"version"`
+	Cases   []struct {
+		Name     string                       `json:"name"`
+		Input    string                       `json:"input"`
+		Expected map[string]map[string]string `json:"expected"`
+		Errors   []string                     `json:"errors"`
+	} `json:"cases"`
+}
+
+func TestDialectConformance(t *testing.T) {
+	data, err := os.ReadFile("fixtures/dialects.json")
+	if err != nil {
+		t.Fatalf("Error reading corpus: %v.", err)
+	}
+	var corpus dialectCorpus
+	if err := json.Unmarshal(data, &corpus); err != nil {
+		t.Fatalf("Error decoding corpus: %v.", err)
+	}
+
+	for _, tt := range corpus.Cases {
+		for dialect, name := range dialectNames {
+			expected, ok := tt.Expected[name]
+			failing := false
+			for _, d := range tt.Errors {
+				failing = failing || d == name
+			}
+			if !ok && !failing {
+				t.Errorf("Case %q has no expectation for dialect %s.", tt.Name, name)
+				continue
+			}
+
+			t.Run(tt.Name+"/"+name, func(t *testing.T) {
+				envMap, err := parseDialect(strings.NewReader(tt.Input), parseConfig{dialect: dialect, env: processEnvironment})
+				if failing {
+					if err == nil {
+						t.Errorf("Expected error, got %+v.", envMap)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("Error: %v.", err)
+				}
+				compareEnvMaps(t, expected, envMap)
+			})
+		}
+	}
+}
+
+func TestDialectOption(t *testing.T) {
+	input := `OPTION_A="a\tb" # comment`
+
+	envMap, _ := Get(Variables("OPTION_A"), FromReader("inline", strings.NewReader(input)), Dialect(ComposeDialect))
+	if envMap["OPTION_A"] != "a\tb" {
+		t.Errorf("Expected compose dialect to be used, got %q.", envMap["OPTION_A"])
+	}
+
+	envMap, _ = Get(Variables("OPTION_A"), FromReader("inline", strings.NewReader(input)), Dialect(SystemdDialect))
+	if envMap["OPTION_A"] != `a\tb# comment` {
+		t.Errorf("Expected systemd dialect to be used, got %q.", envMap["OPTION_A"])
+	}
+
+	envMap, _ = Get(Variables("OPTION_A"), FromReader("config.json", strings.NewReader(`{"OPTION_A": "#1"}`)), Dialect(NodeDialect))
+	if envMap["OPTION_A"] != "#1" {
+		t.Errorf("Expected dialect not to affect JSON sources, got %q.", envMap["OPTION_A"])
+	}
+}
+
+func TestComposeInheritsVariables(t *testing.T) {
+	lookup := func(key string) (string, bool) {
+		if key == "INHERITED" {
+			return "from environment", true
+		}
+		return "", false
+	}
+
+	envMap, err := parseCompose("INHERITED\nMISSING\nREQUIRED=${INHERITED:?must be set}", lookup)
+	if err != nil {
+		t.Fatalf("Error: %v.", err)
+	}
+	compareEnvMaps(t, map[string]string{"INHERITED": "from environment", "REQUIRED": "from environment"}, envMap)
+
+	_, err = parseCompose("A=${MISSING:?must be set}", lookup)
+	if err == nil || !strings.Contains(err.Error(), "must be set") {
+		t.Errorf("Expected required variable error, got %v.", err)
+	}
+}
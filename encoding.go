@@ -0,0 +1,179 @@
+package godotenv
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// decoder converts dotenv content to UTF-8 with "\n" line endings.
+//
+// A UTF-8 byte order mark is dropped. UTF-16 is recognized by its byte order mark or, without one, by a zero byte
+// in the first character, as dotenv files start with ASCII characters. CRLF and lone CR line endings become LF.
+type decoder struct {
+	r        *bufio.Reader
+	detected bool
+	// order is the byte order of UTF-16 content, or nil for UTF-8.
+	order binary.ByteOrder
+	// offset is the number of bytes of UTF-16 content read.
+	offset int64
+	// skipLF is set after a carriage return, so the line feed of CRLF is dropped.
+	skipLF bool
+	// pending holds the part of a decoded character that didn't fit into the buffer of Read.
+	pending []byte
+	buf     [utf8.UTFMax]byte
+}
+
+func newDecoder(r io.Reader) *decoder {
+	// The buffer is only needed to detect the encoding, as large reads bypass it.
+	return &decoder{r: bufio.NewReaderSize(r, 16)}
+}
+
+func (d *decoder) Read(p []byte) (int, error) {
+	if !d.detected {
+		d.detect()
+	}
+
+	n := 0
+	var err error
+	for n == 0 && err == nil && len(p) > 0 {
+		if d.order == nil {
+			n, err = d.r.Read(p)
+			n = d.normalize(p[:n])
+		} else {
+			n, err = d.readUTF16(p)
+		}
+	}
+	return n, err
+}
+
+func (d *decoder) detect() {
+	d.detected = true
+
+	start, _ := d.r.Peek(3)
+	switch {
+	case len(start) >= 3 && start[0] == 0xef && start[1] == 0xbb && start[2] == 0xbf:
+		d.r.Discard(3)
+	case len(start) >= 2 && start[0] == 0xff && start[1] == 0xfe:
+		d.order = binary.LittleEndian
+		d.r.Discard(2)
+		d.offset = 2
+	case len(start) >= 2 && start[0] == 0xfe && start[1] == 0xff:
+		d.order = binary.BigEndian
+		d.r.Discard(2)
+		d.offset = 2
+	case len(start) >= 2 && start[0] != 0 && start[1] == 0:
+		d.order = binary.LittleEndian
+	case len(start) >= 2 && start[0] == 0 && start[1] != 0:
+		d.order = binary.BigEndian
+	}
+}
+
+// normalize converts line endings of b in place and returns the new length.
+func (d *decoder) normalize(b []byte) int {
+	n := 0
+	for _, c := range b {
+		if d.skipLF {
+			d.skipLF = false
+			if c == '\n' {
+				continue
+			}
+		}
+
+		if c == '\r' {
+			c = '\n'
+			d.skipLF = true
+		}
+		b[n] = c
+		n++
+	}
+	return n
+}
+
+func (d *decoder) readUTF16(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(d.pending) > 0 {
+			copied := copy(p[n:], d.pending)
+			d.pending = d.pending[copied:]
+			n += copied
+			continue
+		}
+
+		r, err := d.readRune()
+		if err != nil {
+			return n, err
+		}
+
+		encoded := utf8.AppendRune(d.buf[:0], r)
+		encoded = encoded[:d.normalize(encoded)]
+		d.pending = encoded
+	}
+	return n, nil
+}
+
+// readRune reads a character of UTF-16 content.
+func (d *decoder) readRune() (rune, error) {
+	offset := d.offset
+	unit, err := d.readUnit()
+	if err != nil {
+		return 0, d.error(offset, err)
+	}
+
+	r := rune(unit)
+	if utf16.IsSurrogate(r) {
+		second, err := d.readUnit()
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return 0, d.error(offset, err)
+		}
+
+		if r = utf16.DecodeRune(r, rune(second)); r == utf8.RuneError {
+			return 0, fmt.Errorf("invalid UTF-16 at byte %d: unpaired surrogate", offset)
+		}
+	}
+	return r, nil
+}
+
+// error returns the error for a character at the offset that could not be read.
+// Content that ends in the middle of a character is invalid.
+func (d *decoder) error(offset int64, err error) error {
+	if err == io.ErrUnexpectedEOF {
+		return fmt.Errorf("invalid UTF-16 at byte %d: %w", offset, err)
+	}
+	return err
+}
+
+func (d *decoder) readUnit() (uint16, error) {
+	var unit [2]byte
+	n, err := io.ReadFull(d.r, unit[:])
+	d.offset += int64(n)
+	if err != nil {
+		return 0, err
+	}
+	return d.order.Uint16(unit[:]), nil
+}
+
+// invalidUTF8 returns the column of the first character of the line that is not valid UTF-8, starting at 1,
+// or 0 if the line is valid.
+func invalidUTF8(line []byte) int {
+	if utf8.Valid(line) {
+		return 0
+	}
+
+	column := 1
+	for len(line) > 0 {
+		r, size := utf8.DecodeRune(line)
+		if r == utf8.RuneError && size == 1 {
+			return column
+		}
+		line = line[size:]
+		column++
+	}
+	return 0
+}
@@ -0,0 +1,96 @@
+package godotenv
+
+import (
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"unicode/utf16"
+)
+
+func encodeUTF16(s string, order binary.ByteOrder, bom bool) string {
+	units := utf16.Encode([]rune(s))
+	if bom {
+		units = append([]uint16{0xfeff}, units...)
+	}
+
+	b := make([]byte, 2*len(units))
+	for i, unit := range units {
+		order.PutUint16(b[2*i:], unit)
+	}
+	return string(b)
+}
+
+func TestEncodings(t *testing.T) {
+	content := "OPTION_A=1\r\nOPTION_B=\"é 😀\"\r\nOPTION_C=\"multi\r\nline\"\r\n"
+	expected := map[string]string{"OPTION_A": "1", "OPTION_B": "é 😀", "OPTION_C": "multi\nline"}
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"UTF-8", content},
+		{"UTF-8 with BOM", "\xef\xbb\xbf" + content},
+		{"UTF-16LE with BOM", encodeUTF16(content, binary.LittleEndian, true)},
+		{"UTF-16BE with BOM", encodeUTF16(content, binary.BigEndian, true)},
+		{"UTF-16LE", encodeUTF16(content, binary.LittleEndian, false)},
+		{"UTF-16BE", encodeUTF16(content, binary.BigEndian, false)},
+		{"lone CR", strings.ReplaceAll(content, "\r\n", "\r")},
+		{"LF", strings.ReplaceAll(content, "\r\n", "\n")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envMap, err := parse(strings.NewReader(tt.content))
+			if err != nil {
+				t.Fatalf("Error: %v.", err)
+			}
+			compareEnvMaps(t, expected, envMap)
+
+			// Reading byte by byte splits characters and line endings between reads.
+			envMap, err = parse(iotest.OneByteReader(strings.NewReader(tt.content)))
+			if err != nil {
+				t.Fatalf("Error reading byte by byte: %v.", err)
+			}
+			compareEnvMaps(t, expected, envMap)
+		})
+	}
+}
+
+func TestEncodingsInDialects(t *testing.T) {
+	envMap, err := parseDialect(strings.NewReader(encodeUTF16("OPTION_A=1\r\n", binary.LittleEndian, true)), parseConfig{dialect: ComposeDialect})
+	if err != nil {
+		t.Fatalf("Error: %v.", err)
+	}
+	compareEnvMaps(t, map[string]string{"OPTION_A": "1"}, envMap)
+}
+
+func TestEncodingErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expectedErr string
+	}{
+		{"invalid UTF-8", "OPTION_A=1\nOPTION_B=\"é\xff\"\n", "line 2: invalid UTF-8 at column 12"},
+		{"invalid UTF-8 in a multi-line value", "OPTION_A=\"1\n\xfe\"\n", "line 2: invalid UTF-8 at column 1"},
+		{"odd number of bytes", encodeUTF16("OPTION_A=1", binary.LittleEndian, true) + "x", "invalid UTF-16 at byte 22: unexpected EOF"},
+		{"unpaired surrogate", encodeUTF16("OPTION_A=", binary.BigEndian, true) + "\xd8\x00\x00A", "invalid UTF-16 at byte 20: unpaired surrogate"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parse(strings.NewReader(tt.content))
+			if err == nil || err.Error() != tt.expectedErr {
+				t.Errorf("Expected error %q, got %v.", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestDecoderEmptyInput(t *testing.T) {
+	data, err := io.ReadAll(newDecoder(strings.NewReader("")))
+	if err != nil || len(data) != 0 {
+		t.Errorf("Expected no data, got %q, %v.", data, err)
+	}
+}
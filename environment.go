@@ -0,0 +1,146 @@
+package godotenv
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+)
+
+// environment provides system variables to the System source and to dialects that expand undefined variables.
+type environment struct {
+	// environ lists all variables in the form of os.Environ. It is nil if variables can't be listed.
+	environ func() []string
+	lookup  func(key string) (string, bool)
+}
+
+var processEnvironment = environment{environ: os.Environ, lookup: os.LookupEnv}
+
+// WithEnviron specifies the system environment to use instead of the environment of the process.
+// Variables are given in the form of os.Environ, "key=value".
+func WithEnviron(environ []string) Option {
+	env := newMapEnvironment(environ)
+	return func(cfg *config) {
+		cfg.parse.env = env.environment()
+	}
+}
+
+// WithLookup specifies the function used to look up system variables instead of os.LookupEnv.
+//
+// As variables can't be listed with a lookup function, system variables are only acquired by their names,
+// e.g. with the Variables option, and are not matched by patterns or returned when all variables are requested.
+func WithLookup(lookup func(key string) (string, bool)) Option {
+	return func(cfg *config) {
+		cfg.parse.env = environment{lookup: lookup}
+	}
+}
+
+// mapEnvironment is an environment that can be changed concurrently.
+type mapEnvironment struct {
+	mu        sync.RWMutex
+	variables map[string]string
+}
+
+func newMapEnvironment(environ []string) *mapEnvironment {
+	return &mapEnvironment{variables: environVariables(environ)}
+}
+
+func (m *mapEnvironment) environment() environment {
+	return environment{environ: m.environ, lookup: m.lookup}
+}
+
+func (m *mapEnvironment) environ() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	environ := make([]string, 0, len(m.variables))
+	for k, v := range m.variables {
+		environ = append(environ, k+"="+v)
+	}
+	return environ
+}
+
+func (m *mapEnvironment) lookup(key string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	value, ok := m.variables[key]
+	return value, ok
+}
+
+// environVariables converts variables in the form of os.Environ to a map.
+func environVariables(environ []string) map[string]string {
+	envMap := make(map[string]string, len(environ))
+
+	for _, rawEnvLine := range environ {
+		if keyValue := strings.SplitN(rawEnvLine, "=", 2); len(keyValue) == 2 {
+			envMap[keyValue[0]] = keyValue[1]
+		}
+	}
+
+	return envMap
+}
+
+// Loader gets variables like the package-level functions do, but with its own system environment,
+// so several configurations can be resolved side by side in one process, e.g. in parallel tests.
+//
+// A Loader is safe for concurrent use.
+type Loader struct {
+	env     *mapEnvironment
+	options []Option
+}
+
+// NewLoader returns a Loader with the given system environment in the form of os.Environ.
+// The options are applied before the options of each call.
+//
+//		loader := godotenv.NewLoader([]string{"PORT=8080"}, From("service.env"))
+//		env, notFound := loader.Get(Variables("PORT"))
+func NewLoader(environ []string, options ...Option) *Loader {
+	return &Loader{env: newMapEnvironment(environ), options: options}
+}
+
+// Setenv sets the value of a system variable of the loader.
+func (l *Loader) Setenv(key, value string) {
+	l.env.mu.Lock()
+	defer l.env.mu.Unlock()
+
+	l.env.variables[key] = value
+}
+
+// Unsetenv unsets a system variable of the loader.
+func (l *Loader) Unsetenv(key string) {
+	l.env.mu.Lock()
+	defer l.env.mu.Unlock()
+
+	delete(l.env.variables, key)
+}
+
+// Get works like the Get function.
+func (l *Loader) Get(options ...Option) (envMap map[string]string, notFoundVariables []string) {
+	envMap, notFoundVariables, _ = l.LoadContext(context.Background(), options...)
+	return envMap, notFoundVariables
+}
+
+// Load works like the Load function.
+func (l *Loader) Load(options ...Option) (envMap map[string]string, notFoundVariables []string, err error) {
+	return l.LoadContext(context.Background(), options...)
+}
+
+// LoadContext works like the LoadContext function.
+func (l *Loader) LoadContext(ctx context.Context, options ...Option) (envMap map[string]string, notFoundVariables []string, err error) {
+	return get(ctx, newConfig(l.with(options)))
+}
+
+// Resolve works like the Resolve function.
+func (l *Loader) Resolve(ctx context.Context, options ...Option) ([]Variable, error) {
+	return Resolve(ctx, l.with(options)...)
+}
+
+func (l *Loader) with(options []Option) []Option {
+	all := make([]Option, 0, len(l.options)+len(options)+1)
+	all = append(all, func(cfg *config) {
+		cfg.parse.env = l.env.environment()
+	})
+	all = append(all, l.options...)
+	return append(all, options...)
+}
@@ -0,0 +1,83 @@
+package godotenv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWithEnviron(t *testing.T) {
+	t.Parallel()
+
+	envMap, notFoundVars := Get(Variables("OPTION_A", "OPTION_Z"), From("fixtures/plain.env"), WithEnviron([]string{"OPTION_A=system", "OPTION_Z=8"}), PrioritizeSystem())
+	compareEnvMaps(t, map[string]string{"OPTION_A": "system", "OPTION_Z": "8"}, envMap)
+	if len(notFoundVars) != 0 {
+		t.Errorf("Some of the variables were not found: %+v.", notFoundVars)
+	}
+
+	envMap, _ = Get(Order(System()), WithEnviron([]string{"ONLY=1"}))
+	compareEnvMaps(t, map[string]string{"ONLY": "1"}, envMap)
+}
+
+func TestWithLookup(t *testing.T) {
+	t.Parallel()
+
+	lookup := func(key string) (string, bool) {
+		if key == "OPTION_Z" {
+			return "looked up", true
+		}
+		return "", false
+	}
+
+	envMap, notFoundVars := Get(Variables("OPTION_A", "OPTION_Z", "OPTION_Y"), From("fixtures/plain.env"), WithLookup(lookup))
+	compareEnvMaps(t, map[string]string{"OPTION_A": "1", "OPTION_Z": "looked up"}, envMap)
+	if len(notFoundVars) != 1 || notFoundVars[0] != "OPTION_Y" {
+		t.Errorf("Expected OPTION_Y not to be found, got %+v.", notFoundVars)
+	}
+
+	envMap, _ = Get(Order(System()), WithLookup(lookup))
+	if len(envMap) != 0 {
+		t.Errorf("Expected looked up variables not to be listed, got %+v.", envMap)
+	}
+}
+
+func TestDialectUsesEnviron(t *testing.T) {
+	t.Parallel()
+
+	input := "A=${INJECTED:-default}"
+	for _, dialect := range []DialectKind{ComposeDialect, PythonDialect, ShellDialect} {
+		envMap, _ := Get(Variables("A"), FromReader("inline", strings.NewReader(input)), Dialect(dialect), WithEnviron([]string{"INJECTED=injected"}))
+		if envMap["A"] != "injected" {
+			t.Errorf("Expected %s dialect to use the given environment, got %q.", dialect, envMap["A"])
+		}
+	}
+}
+
+func TestLoadersSideBySide(t *testing.T) {
+	t.Parallel()
+
+	billing := NewLoader([]string{"DB_URL=postgres://billing"}, Variables("DB_URL", "OPTION_A"), From("fixtures/plain.env"))
+	search := NewLoader([]string{"DB_URL=postgres://search"}, Variables("DB_URL"))
+
+	envMap, notFoundVars := billing.Get()
+	compareEnvMaps(t, map[string]string{"DB_URL": "postgres://billing", "OPTION_A": "1"}, envMap)
+	if len(notFoundVars) != 0 {
+		t.Errorf("Some of the variables were not found: %+v.", notFoundVars)
+	}
+
+	envMap, _ = search.Get(From("fixtures/plain.env"))
+	compareEnvMaps(t, map[string]string{"DB_URL": "postgres://search"}, envMap)
+
+	search.Setenv("DB_URL", "postgres://changed")
+	if envMap, _ = search.Get(From("fixtures/plain.env")); envMap["DB_URL"] != "postgres://changed" {
+		t.Errorf("Expected changed variable, got %+v.", envMap)
+	}
+
+	search.Unsetenv("DB_URL")
+	if _, notFoundVars = search.Get(From("fixtures/plain.env")); len(notFoundVars) != 1 {
+		t.Errorf("Expected DB_URL not to be found, got %+v.", notFoundVars)
+	}
+
+	if envMap, _ = billing.Get(); envMap["DB_URL"] != "postgres://billing" {
+		t.Errorf("Expected loaders to be isolated, got %+v.", envMap)
+	}
+}
@@ -0,0 +1,59 @@
+package godotenv
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ContinueOnError makes a dotenv file of the default dialect read to the end, even if some of its entries are invalid.
+// The invalid entries are skipped, and the error returned along with the variables of the valid entries joins
+// the errors of all invalid entries with errors.Join, so all problems of a file can be reported at once:
+//
+//		env, err := godotenv.ParseString(content, godotenv.ContinueOnError())
+//		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+//			for _, err := range joined.Unwrap() {
+//				var parseErr *godotenv.ParseError
+//				if errors.As(err, &parseErr) {
+//					fmt.Printf("%d: %v\n", parseErr.Line, parseErr.Err)
+//				}
+//			}
+//		}
+//
+// Reading stops at errors that don't concern a single entry, such as a file exceeding MaxFileSize or invalid UTF-16.
+func ContinueOnError() Option {
+	return func(cfg *config) {
+		cfg.parse.continueOnError = true
+	}
+}
+
+// ParseError is an error of an entry of a dotenv file of the default dialect.
+type ParseError struct {
+	// Line is the number of the line the entry starts on, starting at 1, or of the line of the entry
+	// that could not be read, e.g. because of invalid UTF-8.
+	Line int
+	// Err is the error of the entry.
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// prefixErrors prefixes the error, or each of the errors joined with errors.Join, with the name of the source.
+func prefixErrors(name string, err error) error {
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+
+	errs := joined.Unwrap()
+	prefixed := make([]error, len(errs))
+	for i, err := range errs {
+		prefixed[i] = fmt.Errorf("%s: %w", name, err)
+	}
+	return errors.Join(prefixed...)
+}
@@ -0,0 +1,130 @@
+package godotenv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// joinedErrors returns the errors joined with errors.Join, or the error itself.
+func joinedErrors(err error) []error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		return joined.Unwrap()
+	}
+	return []error{err}
+}
+
+func TestContinueOnError(t *testing.T) {
+	content := "A=1\n" +
+		"INVALID LINE\n" +
+		"B=\"\\a\"\n" +
+		"C=\xff\n" +
+		"LONG=" + strings.Repeat("x", 10000) + "\n" +
+		"D=\"multi\nline\"\n" +
+		"E=$A\n" +
+		"F=\"unterminated\n" +
+		"G=7\n"
+	expected := map[string]string{"A": "1", "D": "multi\nline", "E": "1"}
+	expectedErrors := []string{
+		"line 2: can't separate key from value",
+		`line 3: value of B: invalid escape sequence "\\a"`,
+		"line 4: invalid UTF-8 at column 3",
+		"line 5: value of LONG: line exceeds the maximum size of 100 bytes",
+		"line 9: unexpected end of file in the value of F",
+	}
+
+	envMap, err := ParseString(content, ContinueOnError(), StrictEscapes(), MaxLineSize(100))
+	compareEnvMaps(t, expected, envMap)
+
+	var messages []string
+	for _, err := range joinedErrors(err) {
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("Expected a ParseError, got %v.", err)
+		}
+		messages = append(messages, err.Error())
+	}
+	if !reflect.DeepEqual(messages, expectedErrors) {
+		t.Errorf("ParseString got the errors wrong: expected %q, got %q.", expectedErrors, messages)
+	}
+}
+
+func TestContinueOnErrorInMultilineValues(t *testing.T) {
+	long := strings.Repeat("x", 10000)
+	tests := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{"invalid line in quotes", "A=\"x\n\xff\ny\"\nB=1\n", "line 2: invalid UTF-8 at column 1"},
+		{"invalid first line in quotes", "A=\"\xff\ny\"\nB=1\n", "line 1: invalid UTF-8 at column 4"},
+		{"invalid line in a heredoc", "A<<EOF\n\xff\nEOF\nB=1\n", "line 2: invalid UTF-8 at column 1"},
+		{"long line in quotes", "A=\"x\n" + long + "\ny\"\nB=1\n", "line 2: value of A: line exceeds the maximum size of 100 bytes"},
+		{"long line closing quotes", "A=\"x\n" + long + "\"\nB=1\n", "line 2: value of A: line exceeds the maximum size of 100 bytes"},
+		{"long first line in quotes", "A=\"" + long + "\ny\"\nB=1\n", "line 1: value of A: line exceeds the maximum size of 100 bytes"},
+		{"long continued line", "A=x \\\n" + long + " \\\ny\nB=1\n", "line 2: value of A: line exceeds the maximum size of 100 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envMap, err := ParseString(tt.content, ContinueOnError(), MaxLineSize(100))
+			compareEnvMaps(t, map[string]string{"B": "1"}, envMap)
+
+			if errs := joinedErrors(err); len(errs) != 1 || errs[0].Error() != tt.err {
+				t.Errorf("Expected exactly the error %q, got %q.", tt.err, errs)
+			}
+		})
+	}
+}
+
+func TestContinueOnErrorStops(t *testing.T) {
+	envMap, err := ParseString("A=1\nINVALID LINE\nB=2\nC=3\n", ContinueOnError(), MaxFileSize(21))
+	compareEnvMaps(t, map[string]string{"A": "1", "B": "2"}, envMap)
+
+	errs := joinedErrors(err)
+	if len(errs) != 2 || errs[0].Error() != "line 2: can't separate key from value" || !errors.Is(errs[1], ErrFileTooLarge) {
+		t.Errorf("ParseString got the errors wrong: %q.", errs)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	envMap, err := ParseString("A=1\n\nINVALID LINE\nB=2\n")
+	if envMap != nil {
+		t.Errorf("Expected no variables on error, got %v.", envMap)
+	}
+
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("Expected a ParseError, got %v.", err)
+	}
+	if parseErr.Line != 3 || parseErr.Err.Error() != "can't separate key from value" {
+		t.Errorf("ParseError got the position wrong: %+v.", parseErr)
+	}
+}
+
+func TestLoadContinueOnError(t *testing.T) {
+	envMap, _, err := Load(FromReader("inline", strings.NewReader("A=1\nFIRST\nB=2\nSECOND\n")), Variables("A", "B"), ContinueOnError())
+	compareEnvMaps(t, map[string]string{"A": "1", "B": "2"}, envMap)
+
+	errs := joinedErrors(err)
+	if len(errs) != 2 || !strings.HasPrefix(errs[0].Error(), "inline: line 2: ") || !strings.HasPrefix(errs[1].Error(), "inline: line 4: ") {
+		t.Errorf("Load got the errors wrong: %q.", errs)
+	}
+}
+
+func TestEntriesContinueOnError(t *testing.T) {
+	var keys []string
+	var errs []error
+	for entry, err := range Entries(strings.NewReader("A=1\nINVALID LINE\nB=2\n"), ContinueOnError()) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		keys = append(keys, entry.Key)
+	}
+
+	if !reflect.DeepEqual(keys, []string{"A", "B"}) || len(errs) != 1 || !strings.HasPrefix(errs[0].Error(), "line 2: ") {
+		t.Errorf("Entries got the entries or errors wrong: %v, %v.", keys, errs)
+	}
+}
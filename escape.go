@@ -0,0 +1,148 @@
+package godotenv
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// StrictEscapes makes unknown escape sequences in double-quoted values an error. By default, the backslash of an unknown
+// escape sequence is dropped, so "\a" is read as "a". It affects the default dialect only.
+//
+// The escape sequences are:
+//
+//		\n, \r and \t: a newline, a carriage return and a tab;
+//		\\, \" and \$: a backslash, a double quote and a dollar sign, which is not expanded;
+//		\xHH: a byte with the hexadecimal value HH;
+//		\uXXXX and \UXXXXXXXX: the Unicode code point in UTF-8;
+//		a backslash at the end of a line: joins the line with the next one.
+func StrictEscapes() Option {
+	return func(cfg *config) {
+		cfg.parse.strictEscapes = true
+	}
+}
+
+// appendUnescaped appends the character of the escape sequence at the start of s to dst
+// and returns the length of the sequence.
+func appendUnescaped(dst []byte, s []byte, strict bool) ([]byte, int, error) {
+	if len(s) < 2 {
+		if strict {
+			return dst, 0, errors.New("unterminated escape sequence")
+		}
+		return append(dst, '\\'), 1, nil
+	}
+
+	switch s[1] {
+	case 'n':
+		return append(dst, '\n'), 2, nil
+	case 'r':
+		return append(dst, '\r'), 2, nil
+	case 't':
+		return append(dst, '\t'), 2, nil
+	case '\\', '"', '$':
+		return append(dst, s[1]), 2, nil
+	case '\n':
+		return dst, 2, nil
+	case 'x':
+		if value, ok := parseHex(s[2:], 2); ok {
+			return append(dst, byte(value)), 4, nil
+		}
+	case 'u':
+		if value, ok := parseHex(s[2:], 4); ok && utf8.ValidRune(rune(value)) {
+			return utf8.AppendRune(dst, rune(value)), 6, nil
+		}
+	case 'U':
+		if value, ok := parseHex(s[2:], 8); ok && utf8.ValidRune(rune(value)) {
+			return utf8.AppendRune(dst, rune(value)), 10, nil
+		}
+	}
+
+	if strict {
+		return dst, 0, fmt.Errorf("invalid escape sequence %q", invalidEscape(s))
+	}
+	// Drop the backslash of an unknown escape sequence.
+	return append(dst, s[1]), 2, nil
+}
+
+// invalidEscape returns the invalid escape sequence at the start of s for an error message.
+func invalidEscape(s []byte) []byte {
+	length := 2
+	switch s[1] {
+	case 'x':
+		length = 4
+	case 'u':
+		length = 6
+	case 'U':
+		length = 10
+	default:
+		_, size := utf8.DecodeRune(s[1:])
+		length = 1 + size
+	}
+
+	if length > len(s) {
+		length = len(s)
+	}
+	return s[:length]
+}
+
+// parseHex parses exactly n hexadecimal digits at the start of s.
+func parseHex(s []byte, n int) (uint32, bool) {
+	if len(s) < n {
+		return 0, false
+	}
+
+	var value uint32
+	for _, c := range s[:n] {
+		switch {
+		case '0' <= c && c <= '9':
+			value = value<<4 | uint32(c-'0')
+		case 'a' <= c && c <= 'f':
+			value = value<<4 | uint32(c-'a'+10)
+		case 'A' <= c && c <= 'F':
+			value = value<<4 | uint32(c-'A'+10)
+		default:
+			return 0, false
+		}
+	}
+	return value, true
+}
+
+// Escape escapes the value for use in a double-quoted dotenv value, so it is read back exactly as it is,
+// even in strict mode. Backslashes, double quotes and dollar signs are escaped, control characters, line separators
+// and bytes of invalid UTF-8 are written as escape sequences, and the result is always on a single line.
+func Escape(value string) string {
+	var sb strings.Builder
+	sb.Grow(len(value))
+
+	for i := 0; i < len(value); {
+		r, size := utf8.DecodeRuneInString(value[i:])
+		switch {
+		case r == '\n':
+			sb.WriteString(`\n`)
+		case r == '\r':
+			sb.WriteString(`\r`)
+		case r == '\t':
+			sb.WriteString(`\t`)
+		case r == '\\' || r == '"' || r == '$':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r == utf8.RuneError && size == 1, r < 0x20, r == 0x7f:
+			fmt.Fprintf(&sb, `\x%02x`, value[i])
+		case r >= 0x80 && r < 0xa0, r == '\u2028', r == '\u2029':
+			fmt.Fprintf(&sb, `\u%04x`, r)
+		default:
+			sb.WriteString(value[i : i+size])
+		}
+		i += size
+	}
+
+	return sb.String()
+}
+
+// Quote returns the value escaped with Escape in double quotes, ready to be written after "KEY=".
+//
+//		fmt.Fprintf(w, "%s=%s\n", key, godotenv.Quote(value))
+func Quote(value string) string {
+	return `"` + Escape(value) + `"`
+}
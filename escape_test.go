@@ -0,0 +1,98 @@
+package godotenv
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestEscapeSequences(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"control characters", `FOO="a\nb\rc\td"`, "a\nb\rc\td"},
+		{"backslash", `FOO="a\\b"`, `a\b`},
+		{"double quote", `FOO="a\"b"`, `a"b`},
+		{"dollar sign", "BAR=1\nFOO=\"\\$BAR\"", "$BAR"},
+		{"variable after an escaped backslash", "BAR=1\nFOO=\"\\\\$BAR\"", `\1`},
+		{"escaped unicode is not expanded", "BAR=1\nFOO=\"\\u0024BAR\"", "$BAR"},
+		{"hexadecimal byte", `FOO="\x41\xff"`, "A\xff"},
+		{"unicode", `FOO="\u00e9\U0001F600"`, "é😀"},
+		{"line continuation", "FOO=\"first \\\nsecond\"", "first second"},
+		{"unknown escape", `FOO="\a\ b"`, "a b"},
+		{"invalid hexadecimal", `FOO="\xZZ"`, "xZZ"},
+		{"surrogate", `FOO="\ud800"`, "ud800"},
+		{"single quotes", `FOO='\t\u00e9'`, `\t\u00e9`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envMap, err := parse(strings.NewReader(tt.input))
+			if err != nil {
+				t.Fatalf("Error: %v.", err)
+			}
+			if envMap["FOO"] != tt.expected {
+				t.Errorf("Expected %q, got %q.", tt.expected, envMap["FOO"])
+			}
+		})
+	}
+}
+
+func TestStrictEscapes(t *testing.T) {
+	tests := []struct {
+		input       string
+		expectedErr string
+	}{
+		{`FOO="\a"`, `line 1: value of FOO: invalid escape sequence "\\a"`},
+		{"OPTION_A=1\nFOO=\"\\xZZ\"", `line 2: value of FOO: invalid escape sequence "\\xZZ"`},
+		{`FOO="\uD800"`, `line 1: value of FOO: invalid escape sequence "\\uD800"`},
+		{`FOO="\U00110000"`, `line 1: value of FOO: invalid escape sequence "\\U00110000"`},
+		{`FOO="\é"`, `line 1: value of FOO: invalid escape sequence "\\é"`},
+		{`FOO="\u12"`, `line 1: value of FOO: invalid escape sequence "\\u12"`},
+	}
+
+	for _, tt := range tests {
+		s := NewScanner(strings.NewReader(tt.input))
+		s.lex.strictEscapes = true
+		_, err := scanAll(s)
+		if err == nil || err.Error() != tt.expectedErr {
+			t.Errorf("Parsing %q: expected error %q, got %v.", tt.input, tt.expectedErr, err)
+		}
+	}
+
+	envMap, _, err := Load(Order(Reader("test.env", strings.NewReader(`FOO="\a"`))), StrictEscapes())
+	if err == nil {
+		t.Errorf("Expected error with the StrictEscapes option, got %+v.", envMap)
+	}
+}
+
+func TestEscapeRoundTrip(t *testing.T) {
+	values := []string{"", "plain", `C:\path`, `say "hi"`, "$HOME ${HOME}", "a\nb\r\n\tc", "\x00\x1f\x7f", "\xff\xfe", "é 😀", "\u2028", "# not a comment", `\`}
+
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		value := make([]byte, random.Intn(16))
+		random.Read(value)
+		values = append(values, string(value))
+	}
+
+	for _, value := range values {
+		line := "FOO=" + Quote(value)
+		if strings.ContainsAny(line, "\n\r") {
+			t.Errorf("Quote(%q) spans several lines: %s.", value, line)
+		}
+
+		s := NewScanner(strings.NewReader(line))
+		s.lex.strictEscapes = true
+		envMap, err := scanAll(s)
+		if err != nil {
+			t.Errorf("Error parsing %s: %v.", line, err)
+			continue
+		}
+		if envMap["FOO"] != value {
+			t.Errorf("Escaped value %q was read back as %q.", value, envMap["FOO"])
+		}
+	}
+}
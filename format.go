@@ -0,0 +1,237 @@
+package godotenv
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// FileFormat is a syntax of the content of dotenv sources.
+type FileFormat int
+
+const (
+	// Auto chooses the format by the extension of the source's name, falling back to Dotenv.
+	Auto FileFormat = iota
+	// Dotenv is the default KEY=value format.
+	Dotenv
+	// JSON is a JSON object.
+	JSON
+	// TOML is a TOML document.
+	TOML
+	// INI is an INI file; keys of a section are prefixed with the section's name.
+	INI
+	// YAML is a YAML document.
+	YAML
+)
+
+const (
+	// DefaultKeySeparator joins the keys of nested values, unless KeySeparator option is used.
+	DefaultKeySeparator = "_"
+	// DefaultListSeparator joins the elements of lists, unless ListSeparator option is used.
+	DefaultListSeparator = ","
+)
+
+// Format specifies the format of all dotenv sources, regardless of their extensions.
+//
+// Without this option, files with .json, .toml, .ini, .yaml and .yml extensions are parsed in the corresponding formats,
+// and all other sources are parsed as dotenv files.
+func Format(format FileFormat) Option {
+	return func(cfg *config) {
+		cfg.parse.format = format
+	}
+}
+
+// KeySeparator specifies the separator used to join the keys of nested values in structured formats,
+// e.g. with "__" separator, {"db": {"host": "x"}} results in the db__host variable.
+func KeySeparator(separator string) Option {
+	return func(cfg *config) {
+		cfg.parse.separator = separator
+	}
+}
+
+// ListSeparator specifies the separator used to join the elements of lists of scalars in structured formats,
+// e.g. with ";" separator, {"hosts": ["a", "b"]} results in hosts=a;b.
+//
+// With an empty separator, each element is stored in its own variable with the element's index as the last part of the key,
+// e.g. hosts_0=a and hosts_1=b. Lists of maps or lists are always stored this way.
+func ListSeparator(separator string) Option {
+	return func(cfg *config) {
+		cfg.parse.listSeparator = separator
+	}
+}
+
+// parseConfig holds the settings of parsing the content of sources.
+type parseConfig struct {
+	format        FileFormat
+	separator     string
+	listSeparator string
+	dialect       DialectKind
+	env           environment
+	maxLineSize   int
+	maxFileSize   int64
+	// commentsAfterWhitespace makes a hash start a comment only after whitespace in the default dialect.
+	commentsAfterWhitespace bool
+	// strictEscapes makes unknown escape sequences an error in the default dialect.
+	strictEscapes bool
+	// continueOnError makes invalid entries of the default dialect skipped instead of stopping the parsing.
+	continueOnError bool
+}
+
+var defaultParseConfig = parseConfig{
+	separator:     DefaultKeySeparator,
+	listSeparator: DefaultListSeparator,
+	env:           processEnvironment,
+}
+
+// formatOf returns the format matching the extension of the name.
+func formatOf(name string) FileFormat {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".json":
+		return JSON
+	case ".toml":
+		return TOML
+	case ".ini":
+		return INI
+	case ".yaml", ".yml":
+		return YAML
+	default:
+		return Dotenv
+	}
+}
+
+// read parses r in the configured format or, if it is not configured, in the given detected format.
+// A parsing error is prefixed with the name of the source.
+func (p parseConfig) read(name string, detected FileFormat, r io.Reader) ([]Entry, error) {
+	envMap, err := p.parseFormat(detected, r)
+	if err != nil {
+		err = prefixErrors(name, err)
+	}
+
+	return entriesOf(envMap), err
+}
+
+// parseFormat parses r in the configured format or, if it is not configured, in the given detected format.
+// On error, it returns no variables, unless invalid entries are skipped with ContinueOnError.
+func (p parseConfig) parseFormat(detected FileFormat, r io.Reader) (map[string]string, error) {
+	format := p.format
+	if format == Auto {
+		format = detected
+	}
+
+	r = limitSize(r, p.maxFileSize)
+
+	var envMap map[string]string
+	var err error
+	switch format {
+	case JSON:
+		envMap, err = parseJSON(r, p)
+	case TOML:
+		envMap, err = parseTOML(r, p)
+	case INI:
+		envMap, err = parseINI(r, p)
+	case YAML:
+		envMap, err = parseYAML(r, p)
+	default:
+		envMap, err = parseDialect(r, p)
+	}
+	if err != nil && !p.continueOnError {
+		return nil, err
+	}
+
+	return envMap, err
+}
+
+func parseJSON(r io.Reader, p parseConfig) (map[string]string, error) {
+	decoder := json.NewDecoder(r)
+	decoder.UseNumber()
+
+	var data interface{}
+	if err := decoder.Decode(&data); err != nil {
+		return nil, err
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("unexpected data after the top-level object")
+	}
+
+	object, ok := data.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("top-level value is not an object")
+	}
+
+	envMap := make(map[string]string)
+	if err := p.flatten(envMap, "", object); err != nil {
+		return nil, err
+	}
+	return envMap, nil
+}
+
+// flatten puts the value into envMap under the key.
+//
+// Keys of nested maps are joined with the key separator. A list of scalars is joined with the list separator,
+// and elements of other lists are stored under their indexes. It fails if two values end up under the same key,
+// e.g. {"a_b": 1, "a": {"b": 2}}.
+func (p parseConfig) flatten(envMap map[string]string, key string, value interface{}) error {
+	join := func(child string) string {
+		if key == "" {
+			return child
+		}
+		return key + p.separator + child
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, child := range v {
+			if err := p.flatten(envMap, join(k), child); err != nil {
+				return err
+			}
+		}
+		return nil
+	case []interface{}:
+		if values, ok := scalars(v); ok && p.listSeparator != "" {
+			return setFlattened(envMap, key, strings.Join(values, p.listSeparator))
+		}
+		for i, child := range v {
+			if err := p.flatten(envMap, join(strconv.Itoa(i)), child); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return setFlattened(envMap, key, scalar(v))
+	}
+}
+
+func setFlattened(envMap map[string]string, key, value string) error {
+	if _, ok := envMap[key]; ok {
+		return fmt.Errorf("key %q is defined more than once", key)
+	}
+	envMap[key] = value
+	return nil
+}
+
+func scalars(list []interface{}) ([]string, bool) {
+	values := make([]string, 0, len(list))
+	for _, v := range list {
+		switch v.(type) {
+		case map[string]interface{}, []interface{}:
+			return nil, false
+		}
+		values = append(values, scalar(v))
+	}
+	return values, true
+}
+
+func scalar(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,145 @@
+package godotenv
+
+import (
+	"strings"
+	"testing"
+)
+
+func compareEnvMaps(t *testing.T, expectedValues, envMap map[string]string) {
+	t.Helper()
+
+	if len(envMap) != len(expectedValues) {
+		t.Errorf("Didn't get the right size map back: expected %d, got %d: %+v.", len(expectedValues), len(envMap), envMap)
+	}
+
+	for key, value := range expectedValues {
+		if actual, ok := envMap[key]; !ok || actual != value {
+			t.Errorf("Read got one of the keys wrong: '%s' should be '%s', not '%s'.", key, value, actual)
+		}
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	envMap, err := read([]Source{Files("fixtures/config.json")})
+	if err != nil {
+		t.Fatalf("Error reading file: %v.", err)
+	}
+
+	compareEnvMaps(t, map[string]string{
+		"OPTION_A":       "json",
+		"OPTION_B":       "2",
+		"OPTION_C":       "true",
+		"OPTION_D":       "",
+		"db_host":        "localhost",
+		"db_port":        "5432",
+		"hosts":          "a,b",
+		"servers_0_name": "x",
+		"servers_1_name": "y",
+	}, envMap)
+}
+
+func TestReadINI(t *testing.T) {
+	envMap, err := read([]Source{Files("fixtures/config.ini")})
+	if err != nil {
+		t.Fatalf("Error reading file: %v.", err)
+	}
+
+	compareEnvMaps(t, map[string]string{
+		"OPTION_A": "ini",
+		"OPTION_B": "quoted ; not a comment",
+		"OPTION_C": "quoted",
+		"OPTION_D": "single # quoted",
+		"OPTION_E": "",
+		"db_host":  "localhost",
+		"db_port":  "5432",
+	}, envMap)
+}
+
+func TestKeySeparator(t *testing.T) {
+	envMap, notFoundVars := Get(Variables("db__host", "db__port"), From("fixtures/config.json"), KeySeparator("__"))
+	if len(notFoundVars) != 0 {
+		t.Errorf("Some of the variables were not found: %+v.", notFoundVars)
+	}
+
+	if envMap["db__host"] != "localhost" || envMap["db__port"] != "5432" {
+		t.Errorf("Read got the values wrong: %+v.", envMap)
+	}
+}
+
+func TestMixedFormats(t *testing.T) {
+	envMap, notFoundVars := Get(Variables("OPTION_A", "OPTION_C", "OPTION_E", "db_host"), From("fixtures/config.json", "fixtures/plain.env"))
+	if len(notFoundVars) != 0 {
+		t.Errorf("Some of the variables were not found: %+v.", notFoundVars)
+	}
+
+	compareEnvMaps(t, map[string]string{
+		"OPTION_A": "1",
+		"OPTION_C": "3",
+		"OPTION_E": "5",
+		"db_host":  "localhost",
+	}, envMap)
+}
+
+func TestFormatOption(t *testing.T) {
+	envMap, notFoundVars := Get(Variables("a_b"), FromReader("inline", strings.NewReader(`{"a": {"b": "c"}}`)), Format(JSON))
+	if len(notFoundVars) != 0 {
+		t.Errorf("Some of the variables were not found: %+v.", notFoundVars)
+	}
+	if envMap["a_b"] != "c" {
+		t.Errorf("Read got the values wrong: %+v.", envMap)
+	}
+
+	envMap, _ = Get(Variables("OPTION_A"), From("fixtures/config.json"), Format(Dotenv))
+	if envMap["OPTION_A"] == "json" {
+		t.Error("Format option should override the extension.")
+	}
+}
+
+func TestFormatErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"array.json", `["a"]`},
+		{"broken.json", `{"a": `},
+		{"trailing.json", `{"a": 1} {}`},
+		{"section.ini", "[db\nhost=x"},
+		{"line.ini", "[db]\nhost"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Load(FromReader(tt.name, strings.NewReader(tt.content)))
+			if err == nil {
+				t.Fatal("Expected error, got nil.")
+			}
+			if !strings.HasPrefix(err.Error(), tt.name+": ") {
+				t.Errorf("Expected error to mention %q, got %q.", tt.name, err)
+			}
+		})
+	}
+}
+
+func TestFlattenedKeyCollisions(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		format  FileFormat
+	}{
+		{"json", `{"a_b": "flat", "a": {"b": "nested"}}`, JSON},
+		{"yaml", "a_b: flat\na:\n  b: nested\n", YAML},
+		{"toml", "a_b = \"flat\"\n[a]\nb = \"nested\"\n", TOML},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Maps are iterated in random order, so the error must not depend on which value comes first.
+			for i := 0; i < 20; i++ {
+				envMap, err := ParseString(tt.content, Format(tt.format))
+				if err == nil || !strings.HasSuffix(err.Error(), `key "a_b" is defined more than once`) {
+					t.Fatalf("Expected an error for the key a_b, got %v and %v.", envMap, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package godotenv
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// addSeeds adds the inputs of the conformance corpus and the dotenv fixtures to the seed corpus.
+func addSeeds(f *testing.F) {
+	for _, tt := range readConformanceCorpus(f).Cases {
+		f.Add(tt.Input)
+	}
+
+	paths, err := filepath.Glob("fixtures/*.env")
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(string(content))
+	}
+}
+
+// formatEnv writes the variables in the default dialect, one per line, sorted by key.
+// Variables with keys that can't be read back are skipped.
+func formatEnv(envMap map[string]string) string {
+	keys := make([]string, 0, len(envMap))
+	for key := range envMap {
+		if writableKey(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, key := range keys {
+		sb.WriteString(key + "=" + Quote(envMap[key]) + "\n")
+	}
+	return sb.String()
+}
+
+// writableKey reports whether a line with the key is read back with the same key, which is not the case e.g. for a key
+// starting with the export keyword. Keys with zero bytes or a leading byte order mark are not written either,
+// as they change the detected encoding at the start of the content.
+func writableKey(key string) bool {
+	parsed, _, err := parseLine(key+"=", map[string]string{})
+	return err == nil && parsed == key && !strings.Contains(key, "\x00") && !strings.HasPrefix(key, "\ufeff")
+}
+
+func FuzzParse(f *testing.F) {
+	addSeeds(f)
+
+	f.Fuzz(func(t *testing.T, input string) {
+		envMap, err := ParseString(input)
+
+		recovered, recoveredErr := ParseString(input, ContinueOnError())
+		if (err == nil) != (recoveredErr == nil) {
+			t.Fatalf("ContinueOnError returned error %v, without it the error is %v.", recoveredErr, err)
+		}
+		if err != nil {
+			return
+		}
+		if !reflect.DeepEqual(recovered, envMap) {
+			t.Fatalf("ContinueOnError got the variables wrong: expected %q, got %q.", envMap, recovered)
+		}
+
+		formatted := formatEnv(envMap)
+		reparsed, err := ParseString(formatted, StrictEscapes())
+		if err != nil {
+			t.Fatalf("Error parsing formatted variables %q: %v.", formatted, err)
+		}
+		for key, value := range envMap {
+			if writableKey(key) && reparsed[key] != value {
+				t.Errorf("Variable %q didn't round-trip: expected %q, got %q.", key, value, reparsed[key])
+			}
+		}
+		if reformatted := formatEnv(reparsed); reformatted != formatted {
+			t.Errorf("Formatting is not idempotent: %q became %q.", formatted, reformatted)
+		}
+	})
+}
+
+func FuzzQuote(f *testing.F) {
+	for _, tt := range readConformanceCorpus(f).Cases {
+		for _, value := range tt.Expected {
+			f.Add(value)
+		}
+	}
+
+	f.Fuzz(func(t *testing.T, value string) {
+		quoted := Quote(value)
+		if strings.ContainsAny(quoted, "\n\r") {
+			t.Fatalf("Quote(%q) returned several lines: %q.", value, quoted)
+		}
+
+		for _, line := range []string{"A=" + quoted, "export A = " + quoted + " # comment"} {
+			envMap, err := ParseString(line, StrictEscapes())
+			if err != nil {
+				t.Fatalf("Error parsing %q: %v.", line, err)
+			}
+			if envMap["A"] != value {
+				t.Errorf("Value didn't round-trip through %q: expected %q, got %q.", line, value, envMap["A"])
+			}
+		}
+	})
+}
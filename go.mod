@@ -1,3 +1,3 @@
 module github.com/alois9866/godotenv
 
-go 1.12
+go 1.23
@@ -23,30 +23,47 @@
 //
 //		godotenv.Get(Variables("ENV_VAR1", "ENV_VAR2"), From("file1", "file2"))
 //
+// Dotenv content can also be read from any io.Reader or fs.FS, e.g. from files embedded into the binary:
+//
+//		//go:embed defaults.env
+//		var defaults embed.FS
+//
+//		godotenv.Get(FromFS(defaults, "defaults.env"), From(".env"))
+//
+// If you need to know why a dotenv file could not be read, use Load instead of Get.
+//
+// Any other provider of variables can implement the Source interface; the Order option defines the precedence of sources:
+//
+//		godotenv.Get(Order(mySecrets, Files(".env"), System()))
+//
 package godotenv
 
 import (
-	"bufio"
+	"context"
 	"errors"
+	"fmt"
 	"io"
-	"os"
-	"regexp"
+	"io/fs"
+	"sort"
 	"strings"
 )
 
-var (
-	singleQuotesRegex  = regexp.MustCompile(`\A'(.*)'\z`)
-	doubleQuotesRegex  = regexp.MustCompile(`\A"(.*)"\z`)
-	escapeRegex        = regexp.MustCompile(`\\.`)
-	unescapeCharsRegex = regexp.MustCompile(`\\([^$])`)
-	exportRegex        = regexp.MustCompile(`^\s*(?:export\s+)?(.*?)\s*$`)
-	expandVarRegex     = regexp.MustCompile(`(\\)?(\$)(\()?{?([A-Z0-9_]+)?}?`)
-)
-
 type config struct {
 	variables   []string
-	filenames   []string
+	required    []string
+	optional    []string
+	schema      []schemaSource
+	prefix      string
+	stripPrefix bool
+	selectors   []selector
+	aliases     []alias
+	deprecated  func(deprecatedName, name string)
+	empty       EmptyMode
+	sources     []Source
+	defaults    []Source
+	order       []Source
 	systemFirst bool
+	parse       parseConfig
 }
 
 type Option func(cfg *config)
@@ -60,6 +77,70 @@ func Variables(variables ...string) Option {
 	}
 }
 
+// WithPrefix specifies that only variables with names starting with the prefix should be acquired.
+//
+// Names given in the Variables option are relative to the prefix: with WithPrefix("BILLING_"),
+// Variables("DB_URL") looks for BILLING_DB_URL.
+func WithPrefix(prefix string) Option {
+	return func(cfg *config) {
+		cfg.prefix = prefix
+	}
+}
+
+// StripPrefix orders to remove the prefix given in the WithPrefix option from names of the acquired variables,
+// so BILLING_DB_URL is returned as DB_URL. Not found variables are reported without the prefix too.
+func StripPrefix() Option {
+	return func(cfg *config) {
+		cfg.stripPrefix = true
+	}
+}
+
+// EmptyMode defines how variables set to an empty string are treated.
+type EmptyMode int
+
+const (
+	// EmptyAsSet treats an empty value as any other value. This is the default.
+	EmptyAsSet EmptyMode = iota
+	// EmptyAsUnset treats variables with empty values as not set, so values of lower precedence sources are used instead.
+	EmptyAsUnset
+	// EmptyAsError reports variables given in the Variables or Required options that are set to empty values as not found,
+	// and makes Load return an error wrapping ErrEmptyValue.
+	EmptyAsError
+)
+
+// ErrEmptyValue is wrapped by the error returned when required variables are empty and EmptyAsError is used.
+var ErrEmptyValue = errors.New("required variables are empty")
+
+// EmptyValues specifies how variables set to an empty string are treated.
+//
+// By default, an empty value is a value: a system variable deliberately set to empty
+// takes precedence over dotenv files when PrioritizeSystem is used, just like os.LookupEnv reports it as set.
+func EmptyValues(mode EmptyMode) Option {
+	return func(cfg *config) {
+		cfg.empty = mode
+	}
+}
+
+// Defaults specifies default values of variables. They have the lowest precedence:
+// a default value is used only if the variable is set neither in the sources nor in the system environment.
+//
+// Names are not relative to the prefix given in the WithPrefix option. If several Defaults... options are given,
+// values from later ones override values from earlier ones. Resolve marks variables with default values.
+func Defaults(values map[string]string) Option {
+	return func(cfg *config) {
+		cfg.defaults = append(cfg.defaults, Map(values))
+	}
+}
+
+// DefaultsFrom works like Defaults, but reads default values from files, e.g. .env.defaults.
+func DefaultsFrom(filePaths ...string) Option {
+	return func(cfg *config) {
+		for _, filePath := range filePaths {
+			cfg.defaults = append(cfg.defaults, fileSource(filePath))
+		}
+	}
+}
+
 // PrioritizeSystem orders to use variable's value from system environment, if it is present both there and in dotenv files.
 func PrioritizeSystem() Option {
 	return func(cfg *config) {
@@ -69,10 +150,33 @@ func PrioritizeSystem() Option {
 
 // From specifies which files or directories should be checked for environment variables.
 //
-// Without this option, the .env file is used by default.
+// Without this option (or FromReader, or FromFS), the .env file is used by default.
+// If several sources are given, variables from later sources override variables from earlier ones.
 func From(filePaths ...string) Option {
 	return func(cfg *config) {
-		cfg.filenames = filePaths
+		for _, filePath := range filePaths {
+			cfg.sources = append(cfg.sources, fileSource(filePath))
+		}
+	}
+}
+
+// FromReader specifies a reader that should be checked for environment variables.
+//
+// The name is used in error messages instead of a file name.
+// The reader is consumed by the first Get... call that uses this option.
+func FromReader(name string, r io.Reader) Option {
+	return func(cfg *config) {
+		cfg.sources = append(cfg.sources, Reader(name, r))
+	}
+}
+
+// FromFS specifies which files of fsys should be checked for environment variables.
+//
+// Patterns are matched with fs.Glob. A pattern that matches nothing is opened as is, so a missing file is reported as an error.
+// This allows to ship default values within the binary using embed.FS, or to use fstest.MapFS in tests.
+func FromFS(fsys fs.FS, patterns ...string) Option {
+	return func(cfg *config) {
+		cfg.sources = append(cfg.sources, FS(fsys, patterns...))
 	}
 }
 
@@ -83,242 +187,330 @@ func From(filePaths ...string) Option {
 //		Variables option: to specify the list of variables to get.
 //		Default: all variables.
 //
+//		Required and Optional options: to get variables that must be set, failing if they are not, and ones that may be set.
+//		Default: no variables are required.
+//
+//		Match and MatchRegexp options: to get variables with names matching patterns, in addition to Variables.
+//		Default: all variables.
+//
+//		WithPrefix option: to get only variables with names starting with the prefix.
+//		Default: any names.
+//
 //		PrioritizeSystem option: to choose system variable's value, if a variable is present in both system environment and dotenv files.
 //		Default: dotenv overrides system.
 //
 //		From option: to get variables from specific files or directories.
 //		Default: from .env file.
 //
+//		Order option: to specify all sources of variables and their precedence explicitly.
+//		Default: dotenv files, then system environment.
+//
+//		Defaults and DefaultsFrom options: to specify values used when a variable is not set anywhere else.
+//		Default: no default values.
+//
+//		MaxLineSize and MaxFileSize options: to limit the size of lines and sources.
+//		Default: no limits.
+//
 func Get(options ...Option) (envMap map[string]string, notFoundVariables []string) {
-	cfg := config{}
+	envMap, notFoundVariables, _ = Load(options...)
+	return envMap, notFoundVariables
+}
+
+// Load works like Get, but it also returns the first error encountered while reading sources.
+//
+// Variables read before the error are still used.
+func Load(options ...Option) (envMap map[string]string, notFoundVariables []string, err error) {
+	return LoadContext(context.Background(), options...)
+}
+
+// LoadContext works like Load, but passes ctx to the sources.
+func LoadContext(ctx context.Context, options ...Option) (envMap map[string]string, notFoundVariables []string, err error) {
+	return get(ctx, newConfig(options))
+}
+
+func newConfig(options []Option) config {
+	cfg := config{parse: defaultParseConfig}
 	for _, op := range options {
 		op(&cfg)
 	}
-	return get(cfg)
+	return cfg
 }
 
-func get(cfg config) (envMap map[string]string, notFoundVariables []string) {
-	inFileVariables, _ := read(filenamesOrDefault(cfg.filenames))
+// Variable is the result of looking up a variable.
+type Variable struct {
+	Name  string
+	Value string
+	// Set reports whether the variable is set, so an unset variable can be told apart from one set to an empty value.
+	Set bool
+	// Default reports whether the value comes from the defaults given with Defaults or DefaultsFrom options.
+	Default bool
+}
 
-	if len(cfg.variables) == 0 {
-		return getAllVariables(inFileVariables, cfg.systemFirst), nil
+// Resolve works like LoadContext, but returns the variables with their states instead of a map and a list of not found
+// variables. The requested variables are returned in the order of the Variables option, all others are sorted by name.
+func Resolve(ctx context.Context, options ...Option) ([]Variable, error) {
+	resolvedVariables, _, err := resolve(ctx, newConfig(options))
+	variables := make([]Variable, 0, len(resolvedVariables))
+	for _, v := range resolvedVariables {
+		variables = append(variables, v.Variable)
 	}
+	return variables, err
+}
 
-	envMap = make(map[string]string)
+func get(ctx context.Context, cfg config) (envMap map[string]string, notFoundVariables []string, err error) {
+	variables, notMatched, err := resolve(ctx, cfg)
 
-	for _, variable := range cfg.variables {
-		set := false
-		value := os.Getenv(variable)
-		if value != "" {
-			envMap[variable] = value
-			if cfg.systemFirst {
-				continue
-			}
-			set = true
+	envMap = make(map[string]string)
+	for _, v := range variables {
+		if v.found {
+			envMap[v.Name] = v.Value
+		} else if !v.optional {
+			notFoundVariables = append(notFoundVariables, v.Name)
 		}
+	}
+
+	return envMap, append(notFoundVariables, notMatched...), err
+}
+
+// resolved is a variable with its lookup result according to the config.
+type resolved struct {
+	Variable
+	found bool
+	// optional variables are not reported as not found.
+	optional bool
+}
+
+// resolve looks up the variables selected by cfg. It also returns required patterns that match nothing.
+func resolve(ctx context.Context, cfg config) (variables []resolved, notMatched []string, err error) {
+	layers, err := cfg.readLayers(ctx)
+	emptyIsUnset := cfg.empty == EmptyAsUnset
+
+	all := cfg.relative(getAllVariables(layers, emptyIsUnset))
+	// fromAll returns a variable from all, given its name relative to the prefix.
+	fromAll := func(name string) resolved {
+		cfg.warnDeprecated(layers, cfg.prefix+name, emptyIsUnset)
+		v := Variable{Name: cfg.resultName(name), Value: all[name], Set: true, Default: isDefault(layers, cfg.prefix+name, emptyIsUnset)}
+		return resolved{Variable: v, found: true}
+	}
 
-		if value, ok := inFileVariables[variable]; ok {
-			envMap[variable] = value
-			continue
+	if len(cfg.variables) == 0 && len(cfg.required) == 0 && len(cfg.optional) == 0 && len(cfg.selectors) == 0 {
+		names := make([]string, 0, len(all))
+		for name := range all {
+			names = append(names, name)
 		}
+		sort.Strings(names)
 
-		if !set {
-			notFoundVariables = append(notFoundVariables, variable)
+		for _, name := range names {
+			if cfg.resultName(name) != "" && (all[name] != "" || !emptyIsUnset) {
+				variables = append(variables, fromAll(name))
+			}
 		}
+		return variables, nil, err
 	}
 
-	return envMap, notFoundVariables
-}
-
-func read(filenames []string) (map[string]string, error) {
-	envMap := make(map[string]string)
+	var empty, missing []string
+	requested := make(map[string]bool)
+	for _, r := range cfg.requests() {
+		value, set := lookup(layers, cfg.prefix+r.name, emptyIsUnset)
+		name := cfg.resultName(r.name)
+		requested[r.name] = true
 
-	for _, filename := range filenames {
-		individualEnvMap, individualErr := readFile(filename)
-		if individualErr != nil {
-			return envMap, individualErr
+		found := set && (value != "" || cfg.empty == EmptyAsSet)
+		if found {
+			cfg.warnDeprecated(layers, cfg.prefix+r.name, emptyIsUnset)
 		}
+		switch {
+		case set && value == "" && cfg.empty == EmptyAsError:
+			empty = append(empty, name)
+		case !found && r.required:
+			missing = append(missing, r.name)
+		}
+		v := Variable{Name: name, Value: value, Set: set, Default: isDefault(layers, cfg.prefix+r.name, emptyIsUnset)}
+		variables = append(variables, resolved{Variable: v, found: found, optional: r.optional})
+	}
 
-		for k, v := range individualEnvMap {
-			envMap[k] = v
+	if len(cfg.selectors) > 0 {
+		matched, notMatchedPatterns, matchErr := cfg.selectMatching(all)
+		for _, name := range matched {
+			if !requested[name] && (all[name] != "" || !emptyIsUnset) {
+				variables = append(variables, fromAll(name))
+			}
+		}
+		notMatched = notMatchedPatterns
+		if err == nil {
+			err = matchErr
 		}
 	}
 
-	return envMap, nil
-}
+	if len(empty) > 0 && err == nil {
+		err = fmt.Errorf("%w: %s", ErrEmptyValue, strings.Join(empty, ", "))
+	}
 
-func filenamesOrDefault(filenames []string) []string {
-	if len(filenames) == 0 {
-		return []string{".env"}
+	if len(missing) > 0 {
+		err = cfg.missingError(missing, err)
 	}
-	return filenames
+
+	return variables, notMatched, err
 }
 
-func readFile(filename string) (map[string]string, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, err
+// relative returns the variables with the configured prefix, keyed by their names without the prefix.
+func (cfg config) relative(envMap map[string]string) map[string]string {
+	if cfg.prefix == "" {
+		return envMap
 	}
-	defer file.Close()
 
-	return parse(file)
+	selected := make(map[string]string)
+	for key, value := range envMap {
+		if strings.HasPrefix(key, cfg.prefix) {
+			selected[strings.TrimPrefix(key, cfg.prefix)] = value
+		}
+	}
+
+	return selected
 }
 
-func parse(r io.Reader) (map[string]string, error) {
-	var lines []string
-	scanner := bufio.NewScanner(r)
-	for scanner.Scan() {
-		lines = append(lines, scanner.Text())
+// resultName returns the name under which a variable is returned, given its name relative to the prefix.
+func (cfg config) resultName(name string) string {
+	if cfg.stripPrefix {
+		return name
 	}
+	return cfg.prefix + name
+}
 
-	err := scanner.Err()
-	if err != nil {
-		return nil, err
+// sourceOrder returns the sources from the highest precedence to the lowest.
+func (cfg config) sourceOrder() []Source {
+	if cfg.order != nil {
+		return cfg.order
 	}
 
-	envMap := make(map[string]string)
-
-	for _, line := range lines {
-		if !isIgnoredLine(line) {
-			k, v, err := parseLine(line, envMap)
-			if err != nil {
-				return envMap, err
-			}
-			envMap[k] = v
-		}
+	files := sourceList(cfg.sources)
+	if len(files) == 0 {
+		files = sourceList{fileSource(".env")}
 	}
 
-	return envMap, err
+	if cfg.systemFirst {
+		return []Source{System(), files}
+	}
+	return []Source{files, System()}
 }
 
-func parseLine(line string, envMap map[string]string) (key string, value string, err error) {
-	line = removeComments(line)
+// layer holds variables of a single source.
+type layer struct {
+	variables map[string]string
+	// defaults is set for the layer of default values.
+	defaults bool
+	// aliased maps names of variables to the deprecated names their values were found under.
+	aliased map[string]string
+	// lookup is used for variables that are not listed, if they can't be listed.
+	lookup func(key string) (string, bool)
+}
 
-	firstEquals := strings.Index(line, "=")
-	firstColon := strings.Index(line, ":")
-	splitString := strings.SplitN(line, "=", 2)
-	if firstColon != -1 && (firstColon < firstEquals || firstEquals == -1) {
-		// This is a yaml-style line.
-		splitString = strings.SplitN(line, ":", 2)
+func (l layer) get(variable string) (string, bool) {
+	if value, ok := l.variables[variable]; ok {
+		return value, true
 	}
-	if len(splitString) != 2 {
-		return "", "", errors.New("can't separate key from value")
+	if l.lookup != nil {
+		return l.lookup(variable)
 	}
-
-	key = exportRegex.ReplaceAllString(splitString[0], "$1")
-	value = parseValue(splitString[1], envMap)
-	return key, value, nil
+	return "", false
 }
 
-// Ditch the comments (but keep quoted hashes).
-func removeComments(line string) string {
-	if strings.Contains(line, "#") {
-		quotesAreOpen := false
-		var segmentsToKeep []string
-		for _, segment := range strings.Split(line, "#") {
-			if strings.Count(segment, `"`) == 1 || strings.Count(segment, `'`) == 1 {
-				if quotesAreOpen {
-					quotesAreOpen = false
-					segmentsToKeep = append(segmentsToKeep, segment)
-				} else {
-					quotesAreOpen = true
-				}
-			}
+// readLayers reads all sources in the order of precedence, followed by the layer of default values.
+func (cfg config) readLayers(ctx context.Context) ([]layer, error) {
+	layers, err := readLayers(ctx, cfg.sourceOrder(), cfg.parse)
 
-			if len(segmentsToKeep) == 0 || quotesAreOpen {
-				segmentsToKeep = append(segmentsToKeep, segment)
-			}
+	if len(cfg.defaults) > 0 {
+		defaults, defaultsErr := readLayers(ctx, []Source{sourceList(cfg.defaults)}, cfg.parse)
+		defaults[0].defaults = true
+		if err == nil {
+			err = defaultsErr
 		}
+		layers = append(layers, defaults...)
+	}
 
-		line = strings.Join(segmentsToKeep, "#")
+	for i := range layers {
+		cfg.applyAliases(&layers[i])
 	}
 
-	return line
+	return layers, err
 }
 
-func parseValue(value string, envMap map[string]string) string {
-	value = strings.Trim(value, " ")
-
-	// Check if we've got quoted values or possible escapes.
-	if len(value) > 1 {
-		singleQuotes := singleQuotesRegex.FindStringSubmatch(value)
-		doubleQuotes := doubleQuotesRegex.FindStringSubmatch(value)
-
-		if singleQuotes != nil || doubleQuotes != nil {
-			// Pull the quotes off the edges.
-			value = value[1 : len(value)-1]
+// readLayers reads all sources and returns their variables from the highest precedence to the lowest.
+//
+// A failing source does not prevent reading the others; the first error is returned.
+func readLayers(ctx context.Context, sources []Source, p parseConfig) ([]layer, error) {
+	var firstErr error
+	layers := make([]layer, 0, len(sources))
+
+	for _, src := range sources {
+		entries, err := loadSource(ctx, src, p)
+		if err != nil && firstErr == nil {
+			firstErr = err
 		}
 
-		if doubleQuotes != nil {
-			// Expand newlines.
-			value = escapeRegex.ReplaceAllStringFunc(value, func(match string) string {
-				c := strings.TrimPrefix(match, `\`)
-				switch c {
-				case "n":
-					return "\n"
-				case "r":
-					return "\r"
-				default:
-					return match
-				}
-			})
-			// Unescape characters.
-			value = unescapeCharsRegex.ReplaceAllString(value, "$1")
+		variables := make(map[string]string, len(entries))
+		for _, entry := range entries {
+			variables[entry.Key] = entry.Value
 		}
 
-		if singleQuotes == nil {
-			value = expandVariables(value, envMap)
+		l := layer{variables: variables}
+		if _, isSystem := src.(systemSource); isSystem && p.env.environ == nil {
+			l.lookup = p.env.lookup
 		}
+		layers = append(layers, l)
 	}
 
-	return value
+	return layers, firstErr
 }
 
-func expandVariables(str string, m map[string]string) string {
-	return expandVarRegex.ReplaceAllStringFunc(str, func(s string) string {
-		submatch := expandVarRegex.FindStringSubmatch(s)
-
-		if submatch == nil {
-			return s
-		}
-		if submatch[1] == `\` || submatch[2] == "(" {
-			return submatch[0][1:]
-		}
-		if submatch[4] != "" {
-			return m[submatch[4]]
+// lookup returns the value of the variable from the layer with the highest precedence that has it.
+// If emptyIsUnset is true, empty values are skipped, unless no layer has a non-empty value; set is true anyway.
+func lookup(layers []layer, variable string, emptyIsUnset bool) (value string, set bool) {
+	for _, l := range layers {
+		if v, ok := l.get(variable); ok {
+			if v != "" || !emptyIsUnset {
+				return v, true
+			}
+			set = true
 		}
-
-		return s
-	})
-}
-
-func isIgnoredLine(line string) bool {
-	trimmedLine := strings.TrimSpace(line)
-	return len(trimmedLine) == 0 || strings.HasPrefix(trimmedLine, "#")
-}
-
-func getAllVariables(fromEnvDotFiles map[string]string, systemFirst bool) map[string]string {
-	envMap := make(map[string]string)
-
-	for k, v := range fromEnvDotFiles {
-		envMap[k] = v
 	}
+	return "", set
+}
 
-	for k, v := range systemVariables() {
-		if _, ok := envMap[k]; ok && systemFirst || !ok {
-			envMap[k] = v
+// origin returns the layer lookup takes the value of the variable from, or nil if the variable is not set.
+// If all values are empty and emptyIsUnset is true, the value is considered taken from the first layer that has it.
+func origin(layers []layer, variable string, emptyIsUnset bool) *layer {
+	var first *layer
+	for i, l := range layers {
+		if value, ok := l.get(variable); ok {
+			if value != "" || !emptyIsUnset {
+				return &layers[i]
+			}
+			if first == nil {
+				first = &layers[i]
+			}
 		}
 	}
+	return first
+}
 
-	return envMap
+// isDefault reports whether lookup takes the value of the variable from the layer of default values.
+func isDefault(layers []layer, variable string, emptyIsUnset bool) bool {
+	l := origin(layers, variable, emptyIsUnset)
+	return l != nil && l.defaults
 }
 
-func systemVariables() map[string]string {
+// getAllVariables merges the layers. If emptyIsUnset is true, empty values don't override values of lower layers.
+func getAllVariables(layers []layer, emptyIsUnset bool) map[string]string {
 	envMap := make(map[string]string)
 
-	for _, rawEnvLine := range os.Environ() {
-		keyValue := strings.SplitN(rawEnvLine, "=", 2)
-		envMap[keyValue[0]] = keyValue[1]
+	for i := len(layers) - 1; i >= 0; i-- {
+		for k, v := range layers[i].variables {
+			if _, ok := envMap[k]; ok && v == "" && emptyIsUnset {
+				continue
+			}
+			envMap[k] = v
+		}
 	}
 
 	return envMap
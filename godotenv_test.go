@@ -2,13 +2,29 @@ package godotenv
 
 import (
 	"bytes"
+	"context"
+	"embed"
+	"errors"
 	"os"
 	"strings"
 	"testing"
+	"testing/fstest"
 )
 
 var noopPresets = make(map[string]string)
 
+//go:embed fixtures/plain.env fixtures/quoted.env
+var embeddedFixtures embed.FS
+
+func read(sources []Source) (map[string]string, error) {
+	entries, err := sourceList(sources).Load(context.Background())
+	envMap := make(map[string]string)
+	for _, entry := range entries {
+		envMap[entry.Key] = entry.Value
+	}
+	return envMap, err
+}
+
 func parseAndCompare(t *testing.T, rawEnvLine string, expectedKey string, expectedValue string) {
 	key, value, _ := parseLine(rawEnvLine, noopPresets)
 	if key != expectedKey || value != expectedValue {
@@ -98,7 +114,7 @@ func TestGetAllFromFileAndSomeFromOutside(t *testing.T) {
 	if err != nil {
 		t.Error("Unable to set env variables for test.")
 	}
-	defer os.Setenv("OPTION_Z", "")
+	defer os.Unsetenv("OPTION_Z")
 
 	envMap, notFoundVars := Get(Variables(expectedVariables...), From(envFileName))
 	if len(notFoundVars) != 0 {
@@ -137,7 +153,7 @@ func TestGetAll(t *testing.T) {
 	if err != nil {
 		t.Error("Unable to set env variables for test.")
 	}
-	defer os.Setenv("OPTION_Z", "")
+	defer os.Unsetenv("OPTION_Z")
 
 	envMap, notFoundVars := Get(Variables(expectedVariables...), From(envFileName))
 	if len(notFoundVars) != 0 {
@@ -178,7 +194,7 @@ func TestGetFail(t *testing.T) {
 	if err != nil {
 		t.Error("Unable to set env variables for test.")
 	}
-	defer os.Setenv("OPTION_Z", "")
+	defer os.Unsetenv("OPTION_Z")
 
 	envMap, notFoundVars := Get(Variables(expectedVariables...), From(envFileName))
 	if len(notFoundVars) == 0 {
@@ -222,7 +238,7 @@ func TestGetCollision(t *testing.T) {
 	if err != nil {
 		t.Error("Unable to set env variables for test.")
 	}
-	defer os.Setenv("OPTION_A", "")
+	defer os.Unsetenv("OPTION_A")
 
 	envMap, notFoundVars := Get(Variables(expectedVariables...), From(envFileName))
 	if len(notFoundVars) != 0 {
@@ -260,7 +276,7 @@ func TestGetCollisionSystemFirst(t *testing.T) {
 	if err != nil {
 		t.Error("Unable to set env variables for test.")
 	}
-	defer os.Setenv("OPTION_A", "")
+	defer os.Unsetenv("OPTION_A")
 
 	envMap, notFoundVars := Get(Variables(expectedVariables...), From(envFileName), PrioritizeSystem())
 	if len(notFoundVars) != 0 {
@@ -317,7 +333,7 @@ func TestReadPlainEnv(t *testing.T) {
 		"OPTION_G": "",
 	}
 
-	envMap, err := read([]string{envFileName})
+	envMap, err := read([]Source{fileSource(envFileName)})
 	if err != nil {
 		t.Error("Error reading file.")
 	}
@@ -434,14 +450,9 @@ func TestParsing(t *testing.T) {
 	// parses single quotes inside double quotes
 	parseAndCompare(t, `FOO="'d'"`, "FOO", `'d'`)
 
-	// parses yaml style options
-	parseAndCompare(t, "OPTION_A: 1", "OPTION_A", "1")
-
-	//parses yaml values with equal signs
-	parseAndCompare(t, "OPTION_A: Foo=bar", "OPTION_A", "Foo=bar")
-
 	// parses non-yaml options with colons
 	parseAndCompare(t, "OPTION_A=1:B", "OPTION_A", "1:B")
+	parseAndCompare(t, "OPTION_A=http://example.com:8080", "OPTION_A", "http://example.com:8080")
 
 	// parses export keyword
 	parseAndCompare(t, "export OPTION_A=2", "OPTION_A", "2")
@@ -509,42 +520,49 @@ func TestParsing(t *testing.T) {
 	if err == nil {
 		t.Errorf("Expected \"%v\" to return error, but it didn't.", badlyFormattedLine)
 	}
+
+	// yaml style lines are only parsed in YAML format
+	yamlLine := "OPTION_A: 1"
+	_, _, err = parseLine(yamlLine, noopPresets)
+	if err == nil {
+		t.Errorf("Expected \"%v\" to return error, but it didn't.", yamlLine)
+	}
 }
 
 func TestLinesToIgnore(t *testing.T) {
 	// it 'ignores empty lines' do
 	// expect(env("\n \t  \nfoo=bar\n \nfizz=buzz")).to eql('foo' => 'bar', 'fizz' => 'buzz')
-	if !isIgnoredLine("\n") {
+	if !isIgnoredLine([]byte("\n")) {
 		t.Error("Line with nothing but line break wasn't ignored.")
 	}
 
-	if !isIgnoredLine("\r\n") {
+	if !isIgnoredLine([]byte("\r\n")) {
 		t.Error("Line with nothing but windows-style line break wasn't ignored.")
 	}
 
-	if !isIgnoredLine("\t\t ") {
+	if !isIgnoredLine([]byte("\t\t ")) {
 		t.Error("Line full of whitespace wasn't ignored.")
 	}
 
 	// it 'ignores comment lines' do
 	// expect(env("\n\n\n # HERE GOES FOO \nfoo=bar")).to eql('foo' => 'bar')
-	if !isIgnoredLine("# comment") {
+	if !isIgnoredLine([]byte("# comment")) {
 		t.Error("Comment wasn't ignored.")
 	}
 
-	if !isIgnoredLine("\t#comment") {
+	if !isIgnoredLine([]byte("\t#comment")) {
 		t.Error("Indented comment wasn't ignored.")
 	}
 
 	// make sure we're not getting false positives
-	if isIgnoredLine(`export OPTION_B='\n'`) {
+	if isIgnoredLine([]byte(`export OPTION_B='\n'`)) {
 		t.Error("ignoring a perfectly valid line to parse.")
 	}
 }
 
 func TestErrorReadDirectory(t *testing.T) {
 	envFilesPath := "fixtures/"
-	envMap, err := read([]string{envFilesPath})
+	envMap, err := read([]Source{fileSource(envFilesPath)})
 
 	if err == nil {
 		t.Errorf("Expected error, got %+v.", envMap)
@@ -553,8 +571,241 @@ func TestErrorReadDirectory(t *testing.T) {
 
 func TestErrorParsing(t *testing.T) {
 	envFilePath := "fixtures/invalid1.env"
-	envMap, err := read([]string{envFilePath})
+	envMap, err := read([]Source{fileSource(envFilePath)})
 	if err == nil {
 		t.Errorf("Expected error, got %+v.", envMap)
 	}
 }
+
+func TestGetFromReader(t *testing.T) {
+	envMap, notFoundVars := Get(Variables("ONE", "TWO"), FromReader("inline", strings.NewReader("ONE=1\nTWO='2'")))
+	if len(notFoundVars) != 0 {
+		t.Errorf("Some of the variables were not found: %+v.", notFoundVars)
+	}
+
+	if envMap["ONE"] != "1" || envMap["TWO"] != "2" {
+		t.Errorf("Read got the values wrong: %+v.", envMap)
+	}
+}
+
+func TestGetFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"config/a.env": {Data: []byte("OPTION_A=1\nOPTION_B=1")},
+		"config/b.env": {Data: []byte("OPTION_B=2")},
+		"other.txt":    {Data: []byte("OPTION_C=3")},
+	}
+
+	envMap, notFoundVars := Get(Variables("OPTION_A", "OPTION_B", "OPTION_C"), FromFS(fsys, "config/*.env"))
+	if len(notFoundVars) != 1 || notFoundVars[0] != "OPTION_C" {
+		t.Errorf("Expected only OPTION_C to not be found, got %+v.", notFoundVars)
+	}
+
+	if envMap["OPTION_A"] != "1" || envMap["OPTION_B"] != "2" {
+		t.Errorf("Read got the values wrong: %+v.", envMap)
+	}
+}
+
+func TestGetFromEmbedFS(t *testing.T) {
+	envMap, notFoundVars := Get(Variables("OPTION_A", "OPTION_I"), FromFS(embeddedFixtures, "fixtures/plain.env", "fixtures/quoted.env"))
+	if len(notFoundVars) != 0 {
+		t.Errorf("Some of the variables were not found: %+v.", notFoundVars)
+	}
+
+	if envMap["OPTION_A"] != "1" || envMap["OPTION_I"] != "echo 'asd'" {
+		t.Errorf("Read got the values wrong: %+v.", envMap)
+	}
+}
+
+func TestSourcesPrecedence(t *testing.T) {
+	envMap, _ := Get(
+		Variables("OPTION_A", "OPTION_B"),
+		From("fixtures/plain.env"),
+		FromReader("override", strings.NewReader("OPTION_B=override")),
+	)
+
+	if envMap["OPTION_A"] != "1" || envMap["OPTION_B"] != "override" {
+		t.Errorf("Later sources should override earlier ones, got %+v.", envMap)
+	}
+}
+
+func TestErrorNamesSource(t *testing.T) {
+	tests := []struct {
+		name   string
+		option Option
+	}{
+		{"fixtures/invalid1.env", From("fixtures/invalid1.env")},
+		{"inline", FromReader("inline", strings.NewReader("INVALID LINE"))},
+		{"bad.env", FromFS(fstest.MapFS{"bad.env": {Data: []byte("INVALID LINE")}}, "bad.env")},
+		{"missing.env", FromFS(fstest.MapFS{}, "missing.env")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Load(tt.option)
+			if err == nil {
+				t.Fatal("Expected error, got nil.")
+			}
+			if !strings.Contains(err.Error(), tt.name) {
+				t.Errorf("Expected error to mention %q, got %q.", tt.name, err)
+			}
+		})
+	}
+}
+
+func TestGetWithPrefix(t *testing.T) {
+	err := os.Setenv("BILLING_SYSTEM", "system")
+	if err != nil {
+		t.Fatalf("Error setting environment variable: %v.", err)
+	}
+	defer os.Unsetenv("BILLING_SYSTEM")
+
+	envMap, _ := Get(From("fixtures/prefixed.env"), WithPrefix("BILLING_"))
+	compareEnvMaps(t, map[string]string{
+		"BILLING_DB_URL": "postgres://billing",
+		"BILLING_TOKEN":  "secret",
+		"BILLING_":       "empty name",
+		"BILLING_SYSTEM": "system",
+	}, envMap)
+
+	envMap, _ = Get(From("fixtures/prefixed.env"), WithPrefix("BILLING_"), StripPrefix())
+	compareEnvMaps(t, map[string]string{
+		"DB_URL": "postgres://billing",
+		"TOKEN":  "secret",
+		"SYSTEM": "system",
+	}, envMap)
+}
+
+func TestGetVariablesWithPrefix(t *testing.T) {
+	envMap, notFoundVars := Get(Variables("DB_URL", "MISSING"), From("fixtures/prefixed.env"), WithPrefix("SEARCH_"))
+	compareEnvMaps(t, map[string]string{"SEARCH_DB_URL": "postgres://search"}, envMap)
+	if len(notFoundVars) != 1 || notFoundVars[0] != "SEARCH_MISSING" {
+		t.Errorf("Expected SEARCH_MISSING not to be found, got %+v.", notFoundVars)
+	}
+
+	envMap, notFoundVars = Get(Variables("DB_URL", "MISSING"), From("fixtures/prefixed.env"), WithPrefix("SEARCH_"), StripPrefix())
+	compareEnvMaps(t, map[string]string{"DB_URL": "postgres://search"}, envMap)
+	if len(notFoundVars) != 1 || notFoundVars[0] != "MISSING" {
+		t.Errorf("Expected MISSING not to be found, got %+v.", notFoundVars)
+	}
+}
+
+func TestGetEmptySystemVariable(t *testing.T) {
+	err := os.Setenv("OPTION_A", "")
+	if err != nil {
+		t.Fatalf("Error setting environment variable: %v.", err)
+	}
+	defer os.Unsetenv("OPTION_A")
+
+	envMap, notFoundVars := Get(Variables("OPTION_A"), From("fixtures/plain.env"), PrioritizeSystem())
+	if len(notFoundVars) != 0 {
+		t.Errorf("Expected empty system variable to be found, got %+v.", notFoundVars)
+	}
+	if value, ok := envMap["OPTION_A"]; !ok || value != "" {
+		t.Errorf("Expected empty system variable to take precedence, got %q.", value)
+	}
+
+	envMap, _ = Get(Variables("OPTION_A"), From("fixtures/plain.env"), PrioritizeSystem(), EmptyValues(EmptyAsUnset))
+	if envMap["OPTION_A"] != "1" {
+		t.Errorf("Expected empty system variable to be treated as unset, got %q.", envMap["OPTION_A"])
+	}
+}
+
+func TestEmptyValues(t *testing.T) {
+	tests := []struct {
+		mode             EmptyMode
+		expectedValues   map[string]string
+		expectedNotFound []string
+		expectedErr      error
+	}{
+		{EmptyAsSet, map[string]string{"OPTION_A": "1", "OPTION_F": ""}, nil, nil},
+		{EmptyAsUnset, map[string]string{"OPTION_A": "1"}, []string{"OPTION_F", "OPTION_Z"}, nil},
+		{EmptyAsError, map[string]string{"OPTION_A": "1"}, []string{"OPTION_F", "OPTION_Z"}, ErrEmptyValue},
+	}
+
+	for _, tt := range tests {
+		envMap, notFoundVars, err := Load(Variables("OPTION_A", "OPTION_F", "OPTION_Z"), From("fixtures/plain.env"), EmptyValues(tt.mode))
+		compareEnvMaps(t, tt.expectedValues, envMap)
+
+		if tt.mode == EmptyAsSet {
+			tt.expectedNotFound = []string{"OPTION_Z"}
+		}
+		if strings.Join(notFoundVars, ",") != strings.Join(tt.expectedNotFound, ",") {
+			t.Errorf("Expected %+v not to be found, got %+v.", tt.expectedNotFound, notFoundVars)
+		}
+		if !errors.Is(err, tt.expectedErr) || (err != nil && !strings.Contains(err.Error(), "OPTION_F")) {
+			t.Errorf("Expected error %v, got %v.", tt.expectedErr, err)
+		}
+	}
+
+	envMap, _ := Get(From("fixtures/plain.env"), EmptyValues(EmptyAsUnset), WithPrefix("OPTION_"))
+	if _, ok := envMap["OPTION_F"]; ok || envMap["OPTION_A"] != "1" {
+		t.Errorf("Expected empty variables to be skipped, got %+v.", envMap)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	variables, err := Resolve(context.Background(), Variables("OPTION_A", "OPTION_F", "OPTION_Z"), From("fixtures/plain.env"), EmptyValues(EmptyAsUnset))
+	if err != nil {
+		t.Fatalf("Error: %v.", err)
+	}
+
+	expected := []Variable{
+		{Name: "OPTION_A", Value: "1", Set: true},
+		{Name: "OPTION_F", Value: "", Set: true},
+		{Name: "OPTION_Z", Value: "", Set: false},
+	}
+	if len(variables) != len(expected) {
+		t.Fatalf("Expected %+v, got %+v.", expected, variables)
+	}
+	for i := range expected {
+		if variables[i] != expected[i] {
+			t.Errorf("Expected %+v, got %+v.", expected[i], variables[i])
+		}
+	}
+}
+
+func TestDefaults(t *testing.T) {
+	err := os.Setenv("OPTION_X", "system")
+	if err != nil {
+		t.Fatalf("Error setting environment variable: %v.", err)
+	}
+	defer os.Unsetenv("OPTION_X")
+
+	envMap, notFoundVars := Get(
+		Variables("OPTION_A", "OPTION_X", "OPTION_Y", "OPTION_Z"),
+		From("fixtures/plain.env"),
+		DefaultsFrom("fixtures/defaults.env"),
+		Defaults(map[string]string{"OPTION_Z": "map"}),
+	)
+	compareEnvMaps(t, map[string]string{
+		"OPTION_A": "1",
+		"OPTION_X": "system",
+		"OPTION_Y": "default y",
+		"OPTION_Z": "map",
+	}, envMap)
+	if len(notFoundVars) != 0 {
+		t.Errorf("Expected defaults to fill in missing variables, got %+v.", notFoundVars)
+	}
+
+	envMap, _ = Get(Order(Map(map[string]string{"OPTION_A": "ordered"})), Defaults(map[string]string{"OPTION_A": "map", "OPTION_Z": "map"}))
+	if envMap["OPTION_A"] != "ordered" || envMap["OPTION_Z"] != "map" {
+		t.Errorf("Expected defaults to have the lowest precedence with Order, got %+v.", envMap)
+	}
+}
+
+func TestResolveDefaults(t *testing.T) {
+	variables, err := Resolve(context.Background(), From("fixtures/plain.env"), WithPrefix("OPTION_"), DefaultsFrom("fixtures/defaults.env"))
+	if err != nil {
+		t.Fatalf("Error: %v.", err)
+	}
+
+	defaults := make(map[string]bool)
+	for _, v := range variables {
+		if v.Default {
+			defaults[v.Name] = true
+		}
+	}
+	if len(defaults) != 2 || !defaults["OPTION_X"] || !defaults["OPTION_Y"] {
+		t.Errorf("Expected OPTION_X and OPTION_Y to be marked as defaults, got %+v.", variables)
+	}
+}
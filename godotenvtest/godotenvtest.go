@@ -0,0 +1,116 @@
+// Package godotenvtest provides helpers for testing code that loads configuration with godotenv.
+//
+// A typical test writes a dotenv file and loads it in an isolated environment:
+//
+//		func TestConfig(t *testing.T) {
+//			godotenvtest.Isolated(t)
+//			path := godotenvtest.WriteEnv(t, "PORT=8080\n")
+//
+//			cfg, err := LoadConfig(path)
+//			...
+//		}
+//
+// Golden compares the variables parsed from a file with a golden JSON file. Run tests with the -godotenvtest.update
+// flag to write the golden files.
+package godotenvtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alois9866/godotenv"
+)
+
+var update = flag.Bool("godotenvtest.update", false, "write golden files of godotenvtest.Golden")
+
+// WriteEnv writes the content to a new file in a temporary directory, which is removed when the test finishes,
+// and returns the path of the file.
+func WriteEnv(t testing.TB, content string) string {
+	t.Helper()
+
+	file, err := os.CreateTemp(t.TempDir(), "*.env")
+	if err != nil {
+		t.Fatalf("Error creating dotenv file: %v.", err)
+	}
+	defer file.Close()
+
+	if _, err := file.WriteString(content); err != nil {
+		t.Fatalf("Error writing dotenv file: %v.", err)
+	}
+	return file.Name()
+}
+
+// Isolated clears the environment of the process for the rest of the test.
+// When the test finishes, the variables are restored and variables set by the test are removed.
+//
+// Like t.Setenv, which it uses, Isolated can't be used in parallel tests.
+func Isolated(t *testing.T) {
+	t.Helper()
+
+	original := make(map[string]bool)
+	t.Cleanup(func() {
+		for _, rawEnvLine := range os.Environ() {
+			if key := environKey(rawEnvLine); key != "" && !original[key] {
+				os.Unsetenv(key)
+			}
+		}
+	})
+
+	for _, rawEnvLine := range os.Environ() {
+		key := environKey(rawEnvLine)
+		// Windows has special variables like "=C:", which can't be changed.
+		if key == "" {
+			continue
+		}
+
+		original[key] = true
+		t.Setenv(key, os.Getenv(key))
+		if err := os.Unsetenv(key); err != nil {
+			t.Fatalf("Error unsetting %s: %v.", key, err)
+		}
+	}
+}
+
+func environKey(rawEnvLine string) string {
+	return strings.SplitN(rawEnvLine, "=", 2)[0]
+}
+
+// Golden parses the dotenv file, without the system environment, and compares the variables with the golden file,
+// which has the same name with the .golden.json extension instead of the extension of the file.
+// The options are applied after the file is given as the only source, e.g. to choose a dialect.
+func Golden(t testing.TB, file string, options ...godotenv.Option) {
+	t.Helper()
+
+	envMap, _, err := godotenv.Load(append([]godotenv.Option{godotenv.Order(godotenv.Files(file))}, options...)...)
+	if err != nil {
+		t.Fatalf("Error parsing %s: %v.", file, err)
+	}
+
+	actual, err := json.MarshalIndent(envMap, "", "  ")
+	if err != nil {
+		t.Fatalf("Error encoding variables: %v.", err)
+	}
+	actual = append(actual, '\n')
+
+	goldenFile := strings.TrimSuffix(file, filepath.Ext(file)) + ".golden.json"
+	if *update {
+		if err := os.WriteFile(goldenFile, actual, 0644); err != nil {
+			t.Fatalf("Error writing golden file: %v.", err)
+		}
+		return
+	}
+
+	expected, err := os.ReadFile(goldenFile)
+	if err != nil {
+		t.Fatalf("Error reading golden file: %v.", err)
+	}
+
+	if !bytes.Equal(bytes.ReplaceAll(expected, []byte("\r\n"), []byte("\n")), actual) {
+		t.Errorf("Variables parsed from %s don't match %s:\ngot:\n%s\nwant:\n%s", file, goldenFile, actual, expected)
+	}
+}
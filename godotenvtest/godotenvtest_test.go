@@ -0,0 +1,49 @@
+package godotenvtest
+
+import (
+	"os"
+	"testing"
+
+	"github.com/alois9866/godotenv"
+)
+
+func TestWriteEnv(t *testing.T) {
+	first := WriteEnv(t, "OPTION_A=1\n")
+	second := WriteEnv(t, "OPTION_A=2\n")
+	if first == second {
+		t.Fatalf("Expected different files, got %s twice.", first)
+	}
+
+	envMap, _, err := godotenv.Load(godotenv.Variables("OPTION_A"), godotenv.From(first, second))
+	if err != nil {
+		t.Fatalf("Error loading files: %v.", err)
+	}
+	if envMap["OPTION_A"] != "2" {
+		t.Errorf("Expected OPTION_A from the second file, got %q.", envMap["OPTION_A"])
+	}
+}
+
+func TestIsolated(t *testing.T) {
+	t.Setenv("GODOTENVTEST_ORIGINAL", "original")
+
+	t.Run("isolated", func(t *testing.T) {
+		Isolated(t)
+
+		if _, ok := os.LookupEnv("GODOTENVTEST_ORIGINAL"); ok {
+			t.Errorf("Expected the environment to be cleared, got %+v.", os.Environ())
+		}
+		os.Setenv("GODOTENVTEST_NEW", "new")
+		os.Setenv("GODOTENVTEST_ORIGINAL", "changed")
+	})
+
+	if value := os.Getenv("GODOTENVTEST_ORIGINAL"); value != "original" {
+		t.Errorf("Expected the variable to be restored, got %q.", value)
+	}
+	if _, ok := os.LookupEnv("GODOTENVTEST_NEW"); ok {
+		t.Error("Expected the variable set in the isolated test to be removed.")
+	}
+}
+
+func TestGolden(t *testing.T) {
+	Golden(t, "testdata/sample.env")
+}
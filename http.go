@@ -0,0 +1,248 @@
+package godotenv
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultURLTimeout is the time a URL source waits for the server, unless URLTimeout option is used.
+const DefaultURLTimeout = 10 * time.Second
+
+// URLOption configures a URL source.
+type URLOption func(s *urlSource)
+
+// URLTimeout limits the time a URL source waits for the server.
+func URLTimeout(timeout time.Duration) URLOption {
+	return func(s *urlSource) {
+		s.timeout = timeout
+	}
+}
+
+// URLCache specifies a directory where a URL source keeps the last fetched content.
+//
+// The cached content is revalidated with the server using ETag and Last-Modified headers,
+// and it is used instead of the server's response when the server is unavailable or fails,
+// in which case the error is returned along with the cached variables.
+func URLCache(dir string) URLOption {
+	return func(s *urlSource) {
+		s.cacheDir = dir
+	}
+}
+
+// URLClient specifies the HTTP client used by a URL source. By default, http.DefaultClient is used.
+func URLClient(client *http.Client) URLOption {
+	return func(s *urlSource) {
+		s.client = client
+	}
+}
+
+// FromURL specifies a URL with dotenv content that should be checked for environment variables.
+//
+// It can be combined with From, FromReader and FromFS; variables from later sources override variables from earlier ones.
+func FromURL(rawURL string, options ...URLOption) Option {
+	src := URL(rawURL, options...)
+	return func(cfg *config) {
+		cfg.sources = append(cfg.sources, src)
+	}
+}
+
+// URL returns a Source that fetches dotenv content from rawURL over HTTP(S).
+//
+// The content is parsed exactly like a file with the same extension. The last fetched content is remembered by the source
+// (and kept in the directory given with URLCache option), so unchanged content is not downloaded again,
+// and the previous content is used, along with the error, when the server is unavailable or fails.
+// Content is not used if ctx is done.
+func URL(rawURL string, options ...URLOption) Source {
+	s := &urlSource{
+		url:     rawURL,
+		timeout: DefaultURLTimeout,
+		client:  http.DefaultClient,
+	}
+	for _, op := range options {
+		op(s)
+	}
+	return s
+}
+
+type urlSource struct {
+	url      string
+	timeout  time.Duration
+	client   *http.Client
+	cacheDir string
+
+	mu     sync.Mutex
+	cached *urlContent
+}
+
+// urlContent is the fetched content with the validators needed to revalidate it.
+type urlContent struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+	Body         []byte `json:"body"`
+}
+
+func (s *urlSource) Load(ctx context.Context) ([]Entry, error) {
+	return s.loadContent(ctx, defaultParseConfig)
+}
+
+func (s *urlSource) loadContent(ctx context.Context, p parseConfig) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	name, format := s.name(), s.format()
+
+	if s.cached == nil {
+		s.cached = s.readCache()
+	}
+
+	content, err := s.fetch(ctx, s.cached)
+	if err != nil {
+		err = fmt.Errorf("%s: %w", name, err)
+		if s.cached == nil || ctx.Err() != nil {
+			return nil, err
+		}
+		// The server is unavailable, so the last known content is used, and the error is reported anyway.
+		entries, readErr := p.read(name, format, bytes.NewReader(s.cached.Body))
+		return entries, errors.Join(err, readErr)
+	}
+
+	entries, err := p.read(name, format, bytes.NewReader(content.Body))
+	if err != nil {
+		return entries, err
+	}
+
+	if content != s.cached {
+		s.cached = content
+		s.writeCache(content)
+	}
+
+	return entries, nil
+}
+
+// fetch downloads the content, or returns cached if the server reports that it is not modified.
+func (s *urlSource) fetch(ctx context.Context, cached *urlContent) (*urlContent, error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if cached != nil {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cached != nil:
+		return cached, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("unexpected status %q", resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &urlContent{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Body:         body,
+	}, nil
+}
+
+// name returns the URL without credentials, to be used in error messages.
+func (s *urlSource) name() string {
+	u, err := url.Parse(s.url)
+	if err != nil {
+		return s.url
+	}
+	return u.Redacted()
+}
+
+// format returns the format matching the extension of the URL's path.
+func (s *urlSource) format() FileFormat {
+	u, err := url.Parse(s.url)
+	if err != nil {
+		return Dotenv
+	}
+	return formatOf(u.Path)
+}
+
+func (s *urlSource) cacheFile() string {
+	sum := sha256.Sum256([]byte(s.url))
+	return filepath.Join(s.cacheDir, "godotenv-"+hex.EncodeToString(sum[:])+".json")
+}
+
+// readCache returns the content cached on disk, or nil if there is none.
+func (s *urlSource) readCache() *urlContent {
+	if s.cacheDir == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.cacheFile())
+	if err != nil {
+		return nil
+	}
+
+	var content urlContent
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil
+	}
+	return &content
+}
+
+// writeCache stores the content on disk. Failing to do so only disables the fallback, so errors are ignored.
+func (s *urlSource) writeCache(content *urlContent) {
+	if s.cacheDir == "" {
+		return
+	}
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		return
+	}
+
+	tmp, err := os.CreateTemp(s.cacheDir, "godotenv-*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return
+	}
+
+	if err := os.Rename(tmp.Name(), s.cacheFile()); err != nil {
+		os.Remove(tmp.Name())
+	}
+}
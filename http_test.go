@@ -0,0 +1,166 @@
+package godotenv
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newDotenvServer(t *testing.T, content string) (*httptest.Server, *int32) {
+	var downloads int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		atomic.AddInt32(&downloads, 1)
+		w.Write([]byte(content))
+	}))
+	t.Cleanup(server.Close)
+	return server, &downloads
+}
+
+func TestGetFromURL(t *testing.T) {
+	server, _ := newDotenvServer(t, "OPTION_A=1\nOPTION_B=\"quoted $OPTION_A\" # comment")
+
+	envMap, notFoundVars, err := Load(Variables("OPTION_A", "OPTION_B"), FromURL(server.URL))
+	if err != nil {
+		t.Fatalf("Error loading from URL: %v.", err)
+	}
+	if len(notFoundVars) != 0 {
+		t.Errorf("Some of the variables were not found: %+v.", notFoundVars)
+	}
+	if envMap["OPTION_A"] != "1" || envMap["OPTION_B"] != "quoted 1" {
+		t.Errorf("Read got the values wrong: %+v.", envMap)
+	}
+}
+
+func TestURLRevalidation(t *testing.T) {
+	server, downloads := newDotenvServer(t, "OPTION_A=1")
+	src := URL(server.URL)
+
+	for i := 0; i < 3; i++ {
+		entries, err := src.Load(context.Background())
+		if err != nil {
+			t.Fatalf("Error loading from URL: %v.", err)
+		}
+		if len(entries) != 1 || entries[0].Value != "1" {
+			t.Errorf("Read got the values wrong: %+v.", entries)
+		}
+	}
+
+	if *downloads != 1 {
+		t.Errorf("Expected content to be downloaded once, got %d downloads.", *downloads)
+	}
+}
+
+func TestURLCacheFallback(t *testing.T) {
+	cacheDir := t.TempDir()
+	server, _ := newDotenvServer(t, "OPTION_A=1")
+
+	_, err := URL(server.URL, URLCache(cacheDir)).Load(context.Background())
+	if err != nil {
+		t.Fatalf("Error loading from URL: %v.", err)
+	}
+	server.Close()
+
+	// A new source has nothing in memory and has to use the cache on disk.
+	entries, err := URL(server.URL, URLCache(cacheDir)).Load(context.Background())
+	if err == nil || !strings.Contains(err.Error(), server.URL) {
+		t.Errorf("Expected the fetch error along with cached content, got %v.", err)
+	}
+	if len(entries) != 1 || entries[0].Value != "1" {
+		t.Errorf("Read got the values wrong: %+v.", entries)
+	}
+
+	// Get ignores the error, so the cached values are still available.
+	envMap, _ := Get(Variables("OPTION_A"), Order(URL(server.URL, URLCache(cacheDir))))
+	if envMap["OPTION_A"] != "1" {
+		t.Errorf("Expected cached value, got %+v.", envMap)
+	}
+
+	_, err = URL(server.URL, URLCache(t.TempDir())).Load(context.Background())
+	if err == nil {
+		t.Error("Expected error without cached content.")
+	}
+}
+
+func TestURLCacheFallbackOnServerError(t *testing.T) {
+	var failing atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			http.Error(w, "failure", http.StatusInternalServerError)
+			return
+		}
+		w.Write([]byte("OPTION_A=1"))
+	}))
+	defer server.Close()
+
+	src := URL(server.URL, URLCache(t.TempDir()))
+	if _, err := src.Load(context.Background()); err != nil {
+		t.Fatalf("Error loading from URL: %v.", err)
+	}
+	failing.Store(true)
+
+	entries, err := src.Load(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("Expected status error, got %v.", err)
+	}
+	if len(entries) != 1 || entries[0].Value != "1" {
+		t.Errorf("Expected cached values along with the error, got %+v.", entries)
+	}
+}
+
+func TestURLCacheNotUsedWhenCanceled(t *testing.T) {
+	cacheDir := t.TempDir()
+	server, _ := newDotenvServer(t, "OPTION_A=1")
+	if _, err := URL(server.URL, URLCache(cacheDir)).Load(context.Background()); err != nil {
+		t.Fatalf("Error loading from URL: %v.", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	envMap, _, err := LoadContext(ctx, Variables("OPTION_A"), Order(URL(server.URL, URLCache(cacheDir))))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected %v, got %v.", context.Canceled, err)
+	}
+	if _, ok := envMap["OPTION_A"]; ok {
+		t.Errorf("Expected no cached values, got %+v.", envMap)
+	}
+}
+
+func TestURLErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/invalid":
+			w.Write([]byte("INVALID LINE"))
+		case "/slow":
+			time.Sleep(100 * time.Millisecond)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	_, err := URL(server.URL + "/missing").Load(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("Expected status error, got %v.", err)
+	}
+
+	_, err = URL(server.URL + "/invalid").Load(context.Background())
+	if err == nil || !strings.Contains(err.Error(), server.URL+"/invalid") {
+		t.Errorf("Expected parsing error naming the URL, got %v.", err)
+	}
+
+	_, err = URL(server.URL+"/slow", URLTimeout(10*time.Millisecond)).Load(context.Background())
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected %v, got %v.", context.DeadlineExceeded, err)
+	}
+}
@@ -0,0 +1,70 @@
+package godotenv
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+func parseINI(r io.Reader, p parseConfig) (map[string]string, error) {
+	envMap := make(map[string]string)
+	section := ""
+
+	lines := &lineReader{r: bufio.NewReader(r), maxLineSize: p.maxLineSize}
+	for lineNumber := 1; ; lineNumber++ {
+		rawLine, err := lines.readLine()
+		if err == io.EOF {
+			return envMap, nil
+		}
+		if err != nil {
+			return envMap, lineError(lineNumber, keyBefore(rawLine, "=:"), err)
+		}
+		line := strings.TrimSpace(string(rawLine))
+
+		switch {
+		case line == "" || line[0] == ';' || line[0] == '#':
+			continue
+		case line[0] == '[':
+			if !strings.HasSuffix(line, "]") {
+				return envMap, fmt.Errorf("line %d: section is not closed", lineNumber)
+			}
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		i := strings.IndexAny(line, "=:")
+		if i < 0 {
+			return envMap, fmt.Errorf("line %d: can't separate key from value", lineNumber)
+		}
+
+		key := strings.TrimSpace(line[:i])
+		if section != "" {
+			key = section + p.separator + key
+		}
+		envMap[key] = parseINIValue(strings.TrimSpace(line[i+1:]))
+	}
+}
+
+// parseINIValue removes an inline comment after the value, and then quotes around it.
+// A comment starts with ";" or "#" at the start of the value or after whitespace outside the quotes.
+func parseINIValue(value string) string {
+	start := 0
+	if len(value) > 1 && (value[0] == '"' || value[0] == '\'') {
+		if end := strings.IndexByte(value[1:], value[0]); end >= 0 {
+			start = end + 2
+		}
+	}
+
+	for i := start; i < len(value); i++ {
+		if (value[i] == ';' || value[i] == '#') && (i == 0 || value[i-1] == ' ' || value[i-1] == '\t') {
+			value = strings.TrimSpace(value[:i])
+			break
+		}
+	}
+
+	if len(value) > 1 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,312 @@
+package godotenv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// lexer reads dotenv files of the default dialect in a single pass, line by line.
+//
+// It reuses its buffers, so for each line only the key and the value are allocated.
+type lexer struct {
+	// commentsAfterWhitespace makes a hash start a comment only if it follows whitespace.
+	commentsAfterWhitespace bool
+	// strictEscapes makes unknown escape sequences in double-quoted values an error.
+	strictEscapes bool
+	// unescaped holds the value of a double-quoted string after processing of escape sequences.
+	unescaped []byte
+	// expanded holds the value after expansion of variables.
+	expanded []byte
+}
+
+// lineParts are the parts of a line split by the lexer.
+type lineParts struct {
+	key []byte
+	// value is the value up to the comment, not trimmed.
+	value []byte
+	// comment is the text after the hash that starts a comment, or nil if there is no comment.
+	comment []byte
+	// separated is true if the line has a separator between the key and the value.
+	separated bool
+	// quote is the quote the value starts with, or 0 if the value is not quoted.
+	quote byte
+	// unterminated is true if the quoted value is not closed on the line.
+	unterminated bool
+}
+
+// States of the lexer within a line.
+const (
+	lexKey = iota
+	lexValueStart
+	lexUnquoted
+	lexQuoted
+	lexAfterQuote
+)
+
+func parse(r io.Reader) (map[string]string, error) {
+	return scanAll(NewScanner(r))
+}
+
+// scanAll returns the variables of all entries of the Scanner.
+func scanAll(s *Scanner) (map[string]string, error) {
+	for s.Scan() {
+	}
+	return s.variables, s.Err()
+}
+
+func parseLine(line string, envMap map[string]string) (key string, value string, err error) {
+	var lex lexer
+	entry, err := lex.parseEntry([]byte(line), envMap)
+	return entry.Key, entry.Value, err
+}
+
+// parseEntry parses a line that is not ignored. Variables are expanded with values from envMap.
+func (lex *lexer) parseEntry(line []byte, envMap map[string]string) (Entry, error) {
+	parts := lex.split(line)
+	if !parts.separated {
+		return Entry{}, errors.New("can't separate key from value")
+	}
+
+	value := parts.value
+	if parts.comment != nil {
+		// The whitespace before an inline comment is not a part of the value.
+		value = bytes.TrimRight(value, " \t")
+	}
+	rawValue := bytes.Trim(value, " ")
+	entry := Entry{Key: string(exportedKey(parts.key)), RawValue: string(rawValue)}
+
+	parsed, err := lex.parseValue(rawValue, envMap)
+	if err != nil {
+		return Entry{}, fmt.Errorf("value of %s: %w", entry.Key, err)
+	}
+
+	// Most values are taken as they are, so they share the string of the raw value.
+	if len(parsed) == len(rawValue) && (len(parsed) == 0 || &parsed[0] == &rawValue[0]) {
+		entry.Value = entry.RawValue
+	} else {
+		entry.Value = string(parsed)
+	}
+
+	if comment := bytes.TrimSpace(parts.comment); len(comment) > 0 {
+		entry.Comment = string(comment)
+	}
+	return entry, nil
+}
+
+// split splits the line into the key, the value and the inline comment.
+//
+// A hash starts a comment anywhere but inside a quoted value, or only after whitespace if commentsAfterWhitespace is set.
+// In double-quoted values, quotes escaped with a backslash don't close the value. A quoted value that is not closed
+// on the line takes the rest of the line, comments included.
+func (lex *lexer) split(line []byte) lineParts {
+	var parts lineParts
+	state := lexKey
+	separator := 0
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if c == '#' && state != lexQuoted && (!lex.commentsAfterWhitespace || i > 0 && (line[i-1] == ' ' || line[i-1] == '\t')) {
+			parts.comment = line[i+1:]
+			line = line[:i]
+			break
+		}
+
+		switch state {
+		case lexKey:
+			if c == '=' {
+				parts.separated = true
+				separator = i
+				state = lexValueStart
+			}
+		case lexValueStart:
+			switch c {
+			case ' ':
+			case '"', '\'':
+				parts.quote = c
+				state = lexQuoted
+			default:
+				state = lexUnquoted
+			}
+		case lexQuoted:
+			switch c {
+			case '\\':
+				if parts.quote == '"' {
+					i++
+				}
+			case parts.quote:
+				state = lexAfterQuote
+			}
+		}
+	}
+
+	parts.unterminated = state == lexQuoted
+	if !parts.separated {
+		parts.key = line
+		return parts
+	}
+	parts.key = line[:separator]
+	parts.value = line[separator+1:]
+	return parts
+}
+
+// exportedKey trims whitespace and the export keyword around the key.
+func exportedKey(key []byte) []byte {
+	key = trimLeftSpace(key)
+	if len(key) > len("export") && bytes.HasPrefix(key, []byte("export")) && isSpace(key[len("export")]) {
+		key = trimLeftSpace(key[len("export"):])
+	}
+	return trimRightSpace(key)
+}
+
+// parseValue unquotes the value trimmed of spaces and expands variables in it.
+// The result is only valid until the next call.
+func (lex *lexer) parseValue(value []byte, envMap map[string]string) ([]byte, error) {
+	// Values of a single character are taken as they are.
+	if len(value) <= 1 {
+		return value, nil
+	}
+
+	switch {
+	case isQuoted(value, '\''):
+		return value[1 : len(value)-1], nil
+	case isQuoted(value, '"'):
+		return lex.unquote(value[1:len(value)-1], envMap)
+	default:
+		return lex.expandVariables(value, envMap), nil
+	}
+}
+
+// isQuoted reports whether the value is enclosed in the quotes.
+func isQuoted(value []byte, quote byte) bool {
+	return len(value) > 1 && value[0] == quote && value[len(value)-1] == quote
+}
+
+// unquote processes escape sequences of a double-quoted value and expands variables in it.
+// Both are done in a single pass, so an escaped dollar sign is never expanded, and an escaped backslash doesn't
+// prevent the expansion of a variable after it.
+func (lex *lexer) unquote(value []byte, envMap map[string]string) ([]byte, error) {
+	if bytes.IndexByte(value, '\\') < 0 && bytes.IndexByte(value, '$') < 0 {
+		return value, nil
+	}
+
+	lex.unescaped = lex.unescaped[:0]
+	for i := 0; i < len(value); {
+		switch value[i] {
+		case '\\':
+			var n int
+			var err error
+			lex.unescaped, n, err = appendUnescaped(lex.unescaped, value[i:], lex.strictEscapes)
+			if err != nil {
+				return nil, err
+			}
+			i += n
+		case '$':
+			name, end := scanVariable(value, i)
+			if len(name) > 0 {
+				lex.unescaped = append(lex.unescaped, envMap[string(name)]...)
+			} else {
+				lex.unescaped = append(lex.unescaped, value[i:end]...)
+			}
+			i = end
+		default:
+			lex.unescaped = append(lex.unescaped, value[i])
+			i++
+		}
+	}
+
+	return lex.unescaped, nil
+}
+
+// expandVariables replaces $NAME and ${NAME} with values of variables defined earlier in the file.
+// Undefined variables expand to an empty string, and \$ is replaced with a literal dollar sign.
+func (lex *lexer) expandVariables(value []byte, envMap map[string]string) []byte {
+	return lex.expand(value, func(name []byte) string {
+		return envMap[string(name)]
+	})
+}
+
+// expand replaces references to variables in the value with the values returned by lookup, like expandVariables.
+func (lex *lexer) expand(value []byte, lookup func(name []byte) string) []byte {
+	if bytes.IndexByte(value, '$') < 0 {
+		return value
+	}
+
+	lex.expanded = lex.expanded[:0]
+	for i := 0; i < len(value); {
+		switch {
+		case value[i] == '\\' && i+1 < len(value) && value[i+1] == '$':
+			_, end := scanVariable(value, i+1)
+			lex.expanded = append(lex.expanded, value[i+1:end]...)
+			i = end
+		case value[i] == '$':
+			name, end := scanVariable(value, i)
+			if len(name) > 0 {
+				lex.expanded = append(lex.expanded, lookup(name)...)
+			} else {
+				lex.expanded = append(lex.expanded, value[i:end]...)
+			}
+			i = end
+		default:
+			lex.expanded = append(lex.expanded, value[i])
+			i++
+		}
+	}
+
+	return lex.expanded
+}
+
+// scanVariable scans a reference to a variable that starts with the dollar sign at the position.
+// It returns the name of the variable, which may be empty, and the position after the reference.
+//
+// Braces are optional on both sides of the name, and an opening parenthesis is skipped, so $(NAME) expands
+// to the value of the variable followed by the closing parenthesis.
+func scanVariable(value []byte, dollar int) (name []byte, end int) {
+	end = dollar + 1
+	if end < len(value) && value[end] == '(' {
+		end++
+	}
+	if end < len(value) && value[end] == '{' {
+		end++
+	}
+
+	start := end
+	for end < len(value) && isVariableNameChar(value[end]) {
+		end++
+	}
+	name = value[start:end]
+
+	if end < len(value) && value[end] == '}' {
+		end++
+	}
+	return name, end
+}
+
+func isVariableNameChar(c byte) bool {
+	return 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || c == '_'
+}
+
+func isIgnoredLine(line []byte) bool {
+	trimmedLine := bytes.TrimSpace(line)
+	return len(trimmedLine) == 0 || trimmedLine[0] == '#'
+}
+
+// isSpace reports whether c is whitespace in the sense of \s of regular expressions.
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\f' || c == '\r'
+}
+
+func trimLeftSpace(b []byte) []byte {
+	for len(b) > 0 && isSpace(b[0]) {
+		b = b[1:]
+	}
+	return b
+}
+
+func trimRightSpace(b []byte) []byte {
+	for len(b) > 0 && isSpace(b[len(b)-1]) {
+		b = b[:len(b)-1]
+	}
+	return b
+}
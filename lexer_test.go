@@ -0,0 +1,324 @@
+package godotenv
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// The regular expression based parser the lexer replaced. It is kept as the reference for the lexer.
+
+var (
+	singleQuotesRegex  = regexp.MustCompile(`\A'(.*)'\z`)
+	doubleQuotesRegex  = regexp.MustCompile(`\A"(.*)"\z`)
+	escapeRegex        = regexp.MustCompile(`\\.`)
+	unescapeCharsRegex = regexp.MustCompile(`\\([^$])`)
+	exportRegex        = regexp.MustCompile(`^\s*(?:export\s+)?(.*?)\s*$`)
+	expandVarRegex     = regexp.MustCompile(`(\\)?(\$)(\()?{?([A-Z0-9_]+)?}?`)
+)
+
+func regexpParse(r io.Reader) (map[string]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	err := scanner.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	envMap := make(map[string]string)
+
+	for _, line := range lines {
+		if !regexpIsIgnoredLine(line) {
+			k, v, err := regexpParseLine(line, envMap)
+			if err != nil {
+				return envMap, err
+			}
+			envMap[k] = v
+		}
+	}
+
+	return envMap, err
+}
+
+func regexpParseLine(line string, envMap map[string]string) (key string, value string, err error) {
+	line = regexpRemoveComments(line)
+
+	splitString := strings.SplitN(line, "=", 2)
+	if len(splitString) != 2 {
+		return "", "", errors.New("can't separate key from value")
+	}
+
+	key = exportRegex.ReplaceAllString(splitString[0], "$1")
+	value = regexpParseValue(splitString[1], envMap)
+	return key, value, nil
+}
+
+// Ditch the comments (but keep quoted hashes).
+func regexpRemoveComments(line string) string {
+	if strings.Contains(line, "#") {
+		quotesAreOpen := false
+		var segmentsToKeep []string
+		for _, segment := range strings.Split(line, "#") {
+			if strings.Count(segment, `"`) == 1 || strings.Count(segment, `'`) == 1 {
+				if quotesAreOpen {
+					quotesAreOpen = false
+					segmentsToKeep = append(segmentsToKeep, segment)
+				} else {
+					quotesAreOpen = true
+				}
+			}
+
+			if len(segmentsToKeep) == 0 || quotesAreOpen {
+				segmentsToKeep = append(segmentsToKeep, segment)
+			}
+		}
+
+		line = strings.Join(segmentsToKeep, "#")
+	}
+
+	return line
+}
+
+func regexpParseValue(value string, envMap map[string]string) string {
+	value = strings.Trim(value, " ")
+
+	// Check if we've got quoted values or possible escapes.
+	if len(value) > 1 {
+		singleQuotes := singleQuotesRegex.FindStringSubmatch(value)
+		doubleQuotes := doubleQuotesRegex.FindStringSubmatch(value)
+
+		if singleQuotes != nil || doubleQuotes != nil {
+			// Pull the quotes off the edges.
+			value = value[1 : len(value)-1]
+		}
+
+		if doubleQuotes != nil {
+			// Expand newlines.
+			value = escapeRegex.ReplaceAllStringFunc(value, func(match string) string {
+				c := strings.TrimPrefix(match, `\`)
+				switch c {
+				case "n":
+					return "\n"
+				case "r":
+					return "\r"
+				default:
+					return match
+				}
+			})
+			// Unescape characters.
+			value = unescapeCharsRegex.ReplaceAllString(value, "$1")
+		}
+
+		if singleQuotes == nil {
+			value = regexpExpandVariables(value, envMap)
+		}
+	}
+
+	return value
+}
+
+func regexpExpandVariables(str string, m map[string]string) string {
+	return expandVarRegex.ReplaceAllStringFunc(str, func(s string) string {
+		submatch := expandVarRegex.FindStringSubmatch(s)
+
+		if submatch == nil {
+			return s
+		}
+		if submatch[1] == `\` || submatch[2] == "(" {
+			return submatch[0][1:]
+		}
+		if submatch[4] != "" {
+			return m[submatch[4]]
+		}
+
+		return s
+	})
+}
+
+func regexpIsIgnoredLine(line string) bool {
+	trimmedLine := strings.TrimSpace(line)
+	return len(trimmedLine) == 0 || strings.HasPrefix(trimmedLine, "#")
+}
+
+func TestLexerMatchesRegexpParser(t *testing.T) {
+	inputs := map[string]string{}
+
+	files, err := filepath.Glob("fixtures/*.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	shellFiles, err := filepath.Glob("fixtures/shell/*.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range append(files, shellFiles...) {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs[file] = string(content)
+	}
+
+	random := rand.New(rand.NewSource(1))
+	for i := 0; i < 2000; i++ {
+		inputs[fmt.Sprintf("random %d", i)] = randomDotenv(random)
+	}
+
+	// Lines are compared one by one, as the regexp parser doesn't support values that span several lines.
+	// Lines with hashes and backslashes are skipped: the regexp parser counted quotes to find comments and knew only
+	// a few escape sequences.
+	for name, input := range inputs {
+		expectedEnv := make(map[string]string)
+		actualEnv := make(map[string]string)
+
+		for _, line := range strings.Split(input, "\n") {
+			line = strings.TrimSuffix(line, "\r")
+			if regexpIsIgnoredLine(line) != isIgnoredLine([]byte(line)) {
+				t.Errorf("%s: isIgnoredLine differs from the regexp parser for %q.", name, line)
+				continue
+			}
+			if isIgnoredLine([]byte(line)) || strings.ContainsAny(line, `#\`) {
+				continue
+			}
+
+			expectedKey, expectedValue, expectedErr := regexpParseLine(line, expectedEnv)
+			key, value, err := parseLine(line, actualEnv)
+			if (expectedErr == nil) != (err == nil) || key != expectedKey || value != expectedValue {
+				t.Errorf("%s: parseLine(%q) returned %q=%q, %v, the regexp parser returned %q=%q, %v.",
+					name, line, key, value, err, expectedKey, expectedValue, expectedErr)
+				break
+			}
+
+			expectedEnv[expectedKey] = expectedValue
+			actualEnv[key] = value
+		}
+	}
+}
+
+// randomDotenv generates lines made of fragments that are significant to the parser.
+func randomDotenv(random *rand.Rand) string {
+	fragments := []string{
+		"A", "FOO", "B_1", "export", "export ", "\t", " ", "=", "#", "# comment", `"`, "'", `\`, `\n`, `\r`, `\$`, `\\`,
+		"$", "$A", "$FOO", "${FOO}", "${", "}", "$(FOO)", "(", "$foo", "\r", "\f", "\v", "é", "\xff", " ",
+	}
+
+	var sb strings.Builder
+	for lines := random.Intn(6); lines >= 0; lines-- {
+		if random.Intn(2) == 0 {
+			sb.WriteString(fragments[random.Intn(len(fragments))] + "=")
+		}
+		quote := []string{"", `"`, "'"}[random.Intn(3)]
+		sb.WriteString(quote)
+		for n := random.Intn(8); n > 0; n-- {
+			sb.WriteString(fragments[random.Intn(len(fragments))])
+		}
+		sb.WriteString(quote + "\n")
+	}
+	return sb.String()
+}
+
+// benchmarkDotenv returns a dotenv file with the given number of lines of typical content.
+func benchmarkDotenv(lines int) string {
+	templates := []string{
+		"# Settings of service %d",
+		"SERVICE_%d_HOST=example.com",
+		"export SERVICE_%d_PORT=8080",
+		"SERVICE_%d_URL=\"http://${SERVICE_0_HOST}:$SERVICE_0_PORT/path\" # with a comment",
+		"SERVICE_%d_PASSWORD='p@ss#word$'",
+		"SERVICE_%d_MOTD=\"Hello,\\nWorld!\"",
+		"",
+	}
+
+	var sb strings.Builder
+	for i := 0; i < lines; i++ {
+		template := templates[i%len(templates)]
+		if strings.Contains(template, "%d") {
+			template = fmt.Sprintf(template, i/len(templates))
+		}
+		sb.WriteString(template + "\n")
+	}
+	return sb.String()
+}
+
+func BenchmarkParse(b *testing.B) {
+	parsers := []struct {
+		name  string
+		parse func(io.Reader) (map[string]string, error)
+	}{
+		{"lexer", parse},
+		{"regexp", regexpParse},
+	}
+
+	for _, lines := range []int{10, 1000} {
+		content := benchmarkDotenv(lines)
+		for _, p := range parsers {
+			b.Run(fmt.Sprintf("%s/%d", p.name, lines), func(b *testing.B) {
+				b.SetBytes(int64(len(content)))
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := p.parse(strings.NewReader(content)); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestInlineComments(t *testing.T) {
+	tests := []struct {
+		line                    string
+		commentsAfterWhitespace bool
+		expectedValue           string
+		expectedComment         string
+	}{
+		{`FOO=bar # comment`, false, "bar", "comment"},
+		{`FOO=bar#comment`, false, "bar", "comment"},
+		{`FOO="it's #1" # rank`, false, "it's #1", "rank"},
+		{`FOO='say "#"' # quotes`, false, `say "#"`, "quotes"},
+		{`FOO="say \"hi\" # not a comment"`, false, `say "hi" # not a comment`, ""},
+		{`FOO="a"#b`, false, "a", "b"},
+		{`FOO=#`, false, "", ""},
+		{`URL=https://example.com/#fragment # link`, false, "https://example.com/", "fragment # link"},
+		{`URL=https://example.com/#fragment # link`, true, "https://example.com/#fragment", "link"},
+		{"URL=https://example.com/#fragment\t# link", true, "https://example.com/#fragment", "link"},
+		{"FOO=bar\t \t# comment", false, "bar", "comment"},
+		{"FOO=bar\t", false, "bar\t", ""},
+		{`FOO=#bar`, true, "#bar", ""},
+		{`FOO= #bar`, true, "", "bar"},
+		{`FOO="a #b" #c`, true, "a #b", "c"},
+	}
+
+	for _, tt := range tests {
+		lex := lexer{commentsAfterWhitespace: tt.commentsAfterWhitespace}
+		entry, err := lex.parseEntry([]byte(tt.line), noopPresets)
+		if err != nil {
+			t.Errorf("Error parsing %q: %v.", tt.line, err)
+			continue
+		}
+		if entry.Value != tt.expectedValue || entry.Comment != tt.expectedComment {
+			t.Errorf("Parsing %q got %q with comment %q, expected %q with comment %q.",
+				tt.line, entry.Value, entry.Comment, tt.expectedValue, tt.expectedComment)
+		}
+	}
+}
+
+func TestCommentsAfterWhitespaceOption(t *testing.T) {
+	content := "URL=https://example.com/#fragment # link\n"
+	envMap, _, err := Load(Order(Reader("test.env", strings.NewReader(content))), CommentsAfterWhitespace())
+	if err != nil {
+		t.Fatalf("Error: %v.", err)
+	}
+	compareEnvMaps(t, map[string]string{"URL": "https://example.com/#fragment"}, envMap)
+}
@@ -0,0 +1,176 @@
+package godotenv
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrLineTooLong is wrapped by the error returned when a line of a dotenv file exceeds the size set with MaxLineSize.
+var ErrLineTooLong = errors.New("line exceeds the maximum size")
+
+// ErrFileTooLarge is wrapped by the error returned when a source exceeds the size set with MaxFileSize.
+var ErrFileTooLarge = errors.New("file exceeds the maximum size")
+
+// MaxLineSize limits the size of lines of dotenv files of the default dialect, INI and YAML files and schema files
+// given with SchemaFrom, in bytes, not counting the line ending. By default, lines can be of any length,
+// e.g. to hold an inline certificate.
+//
+// The error returned for a longer line wraps ErrLineTooLong and names the variable on the line.
+func MaxLineSize(size int) Option {
+	return func(cfg *config) {
+		cfg.parse.maxLineSize = size
+	}
+}
+
+// MaxFileSize limits the size of each file, reader or response read in any format, in bytes.
+// By default, the size is not limited.
+//
+// The error returned for a larger source wraps ErrFileTooLarge. For dotenv files of the default dialect, it names
+// the variable on the line where the limit is reached.
+func MaxFileSize(size int64) Option {
+	return func(cfg *config) {
+		cfg.parse.maxFileSize = size
+	}
+}
+
+// sizeLimitedReader fails with ErrFileTooLarge once more than limit bytes are read.
+type sizeLimitedReader struct {
+	r         io.Reader
+	limit     int64
+	remaining int64
+}
+
+// limitSize returns a reader that reads at most limit bytes from r. A limit of 0 or less means no limit.
+func limitSize(r io.Reader, limit int64) io.Reader {
+	if limit <= 0 {
+		return r
+	}
+	return &sizeLimitedReader{r: r, limit: limit, remaining: limit}
+}
+
+func (l *sizeLimitedReader) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, l.err()
+	}
+
+	// Read one byte more than allowed to find out if the limit is exceeded.
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n - 1, l.err()
+	}
+	return n, err
+}
+
+func (l *sizeLimitedReader) err() error {
+	return fmt.Errorf("%w of %d bytes", ErrFileTooLarge, l.limit)
+}
+
+// lineReader reads lines of any length, like bufio.Scanner does with bufio.ScanLines.
+type lineReader struct {
+	r           *bufio.Reader
+	maxLineSize int
+	// buf holds lines that don't fit into the buffer of r.
+	buf []byte
+	// truncated is set if the rest of a line that is too long is not read yet.
+	truncated bool
+}
+
+// readLine returns the next line without the line ending. The line is only valid until the next call.
+// At the end of the input, it returns io.EOF. Along with other errors, it returns the part of the line read so far.
+func (lr *lineReader) readLine() ([]byte, error) {
+	lr.truncated = false
+	lr.buf = lr.buf[:0]
+	for {
+		chunk, err := lr.r.ReadSlice('\n')
+		if err == nil && len(lr.buf) == 0 {
+			// The whole line is in the buffer of r, which is the usual case.
+			return lr.checkSize(dropLineEnding(chunk))
+		}
+
+		lr.buf = append(lr.buf, chunk...)
+		switch {
+		case err == nil:
+			return lr.checkSize(dropLineEnding(lr.buf))
+		case err == bufio.ErrBufferFull:
+			// A carriage return right before the limit may be a part of the line ending.
+			if lr.maxLineSize > 0 && len(lr.buf) > lr.maxLineSize+1 {
+				lr.truncated = true
+				return lr.buf, lr.errLineTooLong()
+			}
+		case err == io.EOF:
+			if len(lr.buf) == 0 {
+				return nil, io.EOF
+			}
+			return lr.checkSize(dropLineEnding(lr.buf))
+		default:
+			return lr.buf, err
+		}
+	}
+}
+
+// readRest reads the rest of a line that is too long, if it is not read yet, and returns the whole line,
+// which is only valid until the next call to readLine.
+func (lr *lineReader) readRest(line []byte) ([]byte, error) {
+	if !lr.truncated {
+		return line, nil
+	}
+
+	lr.truncated = false
+	for {
+		chunk, err := lr.r.ReadSlice('\n')
+		lr.buf = append(lr.buf, chunk...)
+		switch err {
+		case bufio.ErrBufferFull:
+		case nil, io.EOF:
+			return dropLineEnding(lr.buf), nil
+		default:
+			return nil, err
+		}
+	}
+}
+
+// lineError returns the error for a line of a file in another format than dotenv that could not be read.
+// If a limit is exceeded, the error names the line and the key, if it is known.
+func lineError(lineNumber int, key []byte, err error) error {
+	if !errors.Is(err, ErrLineTooLong) && !errors.Is(err, ErrFileTooLarge) {
+		return err
+	}
+
+	if len(key) > 0 {
+		err = fmt.Errorf("value of %s: %w", key, err)
+	}
+	return fmt.Errorf("line %d: %w", lineNumber, err)
+}
+
+// keyBefore returns the text before the first of the separators on the line, trimmed of whitespace,
+// or nil if there is no separator on the line.
+func keyBefore(line []byte, separators string) []byte {
+	if i := bytes.IndexAny(line, separators); i >= 0 {
+		return bytes.TrimSpace(line[:i])
+	}
+	return nil
+}
+
+func (lr *lineReader) checkSize(line []byte) ([]byte, error) {
+	if lr.maxLineSize > 0 && len(line) > lr.maxLineSize {
+		return line, lr.errLineTooLong()
+	}
+	return line, nil
+}
+
+func (lr *lineReader) errLineTooLong() error {
+	return fmt.Errorf("%w of %d bytes", ErrLineTooLong, lr.maxLineSize)
+}
+
+// dropLineEnding drops a trailing "\n" or "\r\n".
+func dropLineEnding(line []byte) []byte {
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	return bytes.TrimSuffix(line, []byte("\r"))
+}
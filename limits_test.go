@@ -0,0 +1,167 @@
+package godotenv
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLongLines(t *testing.T) {
+	certificate := strings.Repeat("A", 200*1024)
+	envMap, err := parse(strings.NewReader("OPTION_A=1\nCERTIFICATE=\"" + certificate + "\"\nOPTION_B=2\n"))
+	if err != nil {
+		t.Fatalf("Error parsing a long line: %v.", err)
+	}
+
+	expected := map[string]string{"OPTION_A": "1", "CERTIFICATE": certificate, "OPTION_B": "2"}
+	if !reflect.DeepEqual(envMap, expected) {
+		t.Error("Parse got one of the keys wrong on a long line.")
+	}
+}
+
+func TestLongLinesInOtherFormats(t *testing.T) {
+	value := strings.Repeat("A", 70000)
+	tests := []struct {
+		name    string
+		content string
+		format  FileFormat
+	}{
+		{"ini", "[db]\nhost = " + value + "\nport = 5432\n", INI},
+		{"yaml", "db:\n  host: " + value + "\n  port: 5432\n", YAML},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envMap, err := ParseString(tt.content, Format(tt.format))
+			if err != nil {
+				t.Fatalf("Error parsing a long line: %v.", err)
+			}
+			if envMap["db_host"] != value || envMap["db_port"] != "5432" {
+				t.Error("Parse got one of the keys wrong on a long line.")
+			}
+
+			_, err = ParseString(tt.content, Format(tt.format), MaxLineSize(10))
+			expectedErr := "line 2: value of host: line exceeds the maximum size of 10 bytes"
+			if !errors.Is(err, ErrLineTooLong) || err.Error() != expectedErr {
+				t.Errorf("Expected error %q, got %v.", expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestLongLinesInSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".env.example")
+	if err := os.WriteFile(path, []byte("# "+strings.Repeat("A", 70000)+"\nOPTION_A=1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	descriptions, err := readSchema(path, 0)
+	if err != nil || len(descriptions["OPTION_A"]) != 70000 {
+		t.Errorf("Error reading a long line of a schema: %v.", err)
+	}
+
+	_, _, err = Load(Order(Map(nil)), Required("OPTION_B"), SchemaFrom(path), MaxLineSize(10))
+	if !errors.Is(err, ErrLineTooLong) || !strings.Contains(err.Error(), path+": line 1: line exceeds the maximum size of 10 bytes") {
+		t.Errorf("Expected an error for the long line, got %v.", err)
+	}
+}
+
+func TestMaxLineSize(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		expectedErr string
+	}{
+		{"line within the limit", "OPTION_A=12345678\r\nOPTION_B=2\n", ""},
+		{"line over the limit", "OPTION_A=1\nexport CERTIFICATE=1234\n", "line 2: value of CERTIFICATE: line exceeds the maximum size of 18 bytes"},
+		{"line without a key", "OPTION_A=1\n" + strings.Repeat("#", 100), "line 2: line exceeds the maximum size of 18 bytes"},
+		{"long line over the limit", "OPTION_A=" + strings.Repeat("1", 10000), "line 1: value of OPTION_A: line exceeds the maximum size of 18 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Load(Order(Reader("test.env", strings.NewReader(tt.content))), MaxLineSize(18))
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v.", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrLineTooLong) || !strings.HasSuffix(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error %q, got %v.", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestMaxFileSize(t *testing.T) {
+	tests := []struct {
+		name        string
+		content     string
+		options     []Option
+		expectedErr string
+	}{
+		{"dotenv file within the limit", "OPTION_A=1\nOPTION_B=2\n", nil, ""},
+		{"dotenv file over the limit", "OPTION_A=1\nOPTION_B=22\n", nil, "line 2: value of OPTION_B: file exceeds the maximum size of 22 bytes"},
+		{"json file over the limit", `{"OPTION_A": "1", "OPTION_B": "2"}`, []Option{Format(JSON)}, "file exceeds the maximum size of 22 bytes"},
+		{"dialect over the limit", "OPTION_A=1\nOPTION_B=22\n", []Option{Dialect(ComposeDialect)}, "file exceeds the maximum size of 22 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := append([]Option{Order(Reader("test.env", strings.NewReader(tt.content))), MaxFileSize(22)}, tt.options...)
+			_, _, err := Load(options...)
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Errorf("Unexpected error: %v.", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, ErrFileTooLarge) || !strings.HasSuffix(err.Error(), tt.expectedErr) {
+				t.Errorf("Expected error %q, got %v.", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestLineReaderMatchesScanner(t *testing.T) {
+	inputs := []string{
+		"",
+		"\n",
+		"A=1",
+		"A=1\nB=2\n",
+		"A=1\r\nB=2\r\n",
+		"A=1\r",
+		"A=1\r\r\n\n\r",
+		"\n\nA=" + strings.Repeat("x", 40) + "\r\n" + strings.Repeat("y", 15) + "\r",
+	}
+
+	for _, input := range inputs {
+		var expected []string
+		scanner := bufio.NewScanner(strings.NewReader(input))
+		for scanner.Scan() {
+			expected = append(expected, scanner.Text())
+		}
+
+		// The smallest buffer makes long lines span several reads.
+		lines := lineReader{r: bufio.NewReaderSize(strings.NewReader(input), 16)}
+		var actual []string
+		for {
+			line, err := lines.readLine()
+			if err != nil {
+				break
+			}
+			actual = append(actual, string(line))
+		}
+
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("lineReader split %q into %q, bufio.Scanner into %q.", input, actual, expected)
+		}
+	}
+}
@@ -0,0 +1,190 @@
+package godotenv
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var errUnterminated = errors.New("unexpected end of file in the value")
+
+// scanEntry parses the entry that starts on the line, reading the following lines if the entry spans several lines:
+//
+//		a quoted value that is not closed on the line continues up to the closing quote, including the line breaks;
+//		a line with an unquoted value that ends with a backslash continues on the next line, without the backslash and the line break;
+//		KEY<<EOF starts a heredoc that ends with a line consisting of the delimiter only; variables are not expanded if the delimiter is quoted.
+//
+// Errors name the line the entry starts on. With ContinueOnError, lines that can't be read don't stop the scanning
+// of the entry, so an invalid entry is skipped up to its end; lineErr is the error of the first line, if any.
+// The first error of the entry is returned.
+func (s *Scanner) scanEntry(line []byte, lineErr error) (Entry, error) {
+	start := s.line
+	s.entryErr = lineErr
+
+	if key, delimiter, expand, ok := heredoc(line); ok {
+		entry, err := s.scanHeredoc(key, delimiter, expand)
+		if s.entryErr != nil {
+			return Entry{}, s.entryErr
+		}
+		return entry, err
+	}
+
+	var err error
+	if parts := s.lex.split(line); parts.unterminated {
+		line, err = s.scanQuoted(line, parts.quote)
+	} else if continues(line, parts) {
+		line, err = s.scanContinued(line)
+	}
+	if s.entryErr != nil {
+		err = s.entryErr
+	}
+	if err != nil {
+		return Entry{}, err
+	}
+
+	entry, err := s.lex.parseEntry(line, s.variables)
+	if err != nil {
+		return Entry{}, &ParseError{Line: start, Err: err}
+	}
+	return entry, nil
+}
+
+// nextLine reads the next line of the entry with the key that starts on the line start.
+func (s *Scanner) nextLine(key []byte, start int) ([]byte, error) {
+	s.line++
+	line, err := s.lines.readLine()
+	if err == io.EOF {
+		return nil, &ParseError{Line: start, Err: fmt.Errorf("%w of %s", errUnterminated, key)}
+	}
+	if err == nil {
+		err = s.checkEncoding(line)
+	}
+	if err != nil {
+		line, err = s.recoverLine(line, s.readError(key, err))
+		if !s.continueOnError || !recoverable(err) {
+			return nil, err
+		}
+		// Read on to the end of the entry, which is skipped because of the error.
+		if s.entryErr == nil {
+			s.entryErr = err
+		}
+	}
+	return line, nil
+}
+
+// scanQuoted reads lines up to the one that closes the quoted value started on the line.
+func (s *Scanner) scanQuoted(line []byte, quote byte) ([]byte, error) {
+	start := s.line
+	s.buf = append(s.buf[:0], line...)
+
+	for {
+		next, err := s.nextLine(keyOf(s.buf), start)
+		if err != nil {
+			return nil, err
+		}
+
+		s.buf = append(append(s.buf, '\n'), next...)
+		if closesQuote(next, quote) {
+			return s.buf, nil
+		}
+	}
+}
+
+// scanContinued joins the line with the following lines while they end with a backslash.
+func (s *Scanner) scanContinued(line []byte) ([]byte, error) {
+	start := s.line
+	s.buf = append(s.buf[:0], line...)
+
+	for bytes.HasSuffix(s.buf, []byte(`\`)) {
+		s.buf = s.buf[:len(s.buf)-1]
+		next, err := s.nextLine(keyOf(s.buf), start)
+		if err != nil {
+			return nil, err
+		}
+		s.buf = append(s.buf, next...)
+	}
+
+	return s.buf, nil
+}
+
+// scanHeredoc reads the lines of a heredoc up to the delimiter.
+func (s *Scanner) scanHeredoc(key, delimiter string, expand bool) (Entry, error) {
+	start := s.line
+
+	s.buf = s.buf[:0]
+	for lines := 0; ; lines++ {
+		next, err := s.nextLine([]byte(key), start)
+		if err != nil {
+			return Entry{}, err
+		}
+		if string(next) == delimiter {
+			break
+		}
+
+		if lines > 0 {
+			s.buf = append(s.buf, '\n')
+		}
+		s.buf = append(s.buf, next...)
+	}
+
+	entry := Entry{Key: key, RawValue: string(s.buf)}
+	entry.Value = entry.RawValue
+	if expand {
+		entry.Value = string(s.lex.expandVariables(s.buf, s.variables))
+	}
+	return entry, nil
+}
+
+// closesQuote reports whether the text has the closing quote. In double quotes, quotes escaped with a backslash are skipped.
+func closesQuote(text []byte, quote byte) bool {
+	for i := 0; i < len(text); i++ {
+		switch {
+		case text[i] == '\\' && quote == '"':
+			i++
+		case text[i] == quote:
+			return true
+		}
+	}
+	return false
+}
+
+// continues reports whether the line, with an unquoted value, ends with a backslash that continues it on the next line.
+// A backslash at the end of a comment doesn't continue the line.
+func continues(line []byte, parts lineParts) bool {
+	return parts.separated && parts.quote == 0 && parts.comment == nil && bytes.HasSuffix(line, []byte(`\`))
+}
+
+// heredoc parses the first line of a heredoc, KEY<<DELIMITER, where the delimiter may be quoted.
+// The key and the delimiter are copied, as the line is overwritten by the following lines.
+func heredoc(line []byte) (key, delimiter string, expand bool, ok bool) {
+	operator := bytes.Index(line, []byte("<<"))
+	if operator < 0 || bytes.IndexByte(line[:operator], '=') >= 0 {
+		return "", "", false, false
+	}
+
+	k := exportedKey(line[:operator])
+	d := trimRightSpace(trimLeftSpace(line[operator+2:]))
+	expand = true
+	if len(d) > 2 && (d[0] == '\'' || d[0] == '"') && d[len(d)-1] == d[0] {
+		d = d[1 : len(d)-1]
+		expand = false
+	}
+
+	if len(k) == 0 || bytes.IndexAny(k, " \t\"'") >= 0 || !isHeredocDelimiter(d) {
+		return "", "", false, false
+	}
+	return string(k), string(d), expand, true
+}
+
+func isHeredocDelimiter(delimiter []byte) bool {
+	if len(delimiter) == 0 {
+		return false
+	}
+	for _, c := range delimiter {
+		if !isVariableNameChar(c) && !('a' <= c && c <= 'z') {
+			return false
+		}
+	}
+	return true
+}
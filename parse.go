@@ -0,0 +1,65 @@
+package godotenv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// ParseOption is an option of Parse, ParseString, NewScanner and Entries. The options that affect parsing are Format,
+// Dialect, KeySeparator, ListSeparator, CommentsAfterWhitespace, StrictEscapes, ContinueOnError, MaxLineSize,
+// MaxFileSize, WithEnviron and WithLookup; NewScanner and Entries only support some of them. Other options, such as
+// From or Required, are rejected with an error wrapping ErrNotParseOption.
+type ParseOption = Option
+
+// ErrNotParseOption is wrapped by the error returned when an option that doesn't affect parsing is given to Parse,
+// ParseString, NewScanner or Entries.
+var ErrNotParseOption = errors.New("option doesn't affect parsing")
+
+// parseConfigOf returns the parsing settings of the options. It fails if an option changes other settings.
+func parseConfigOf(options []ParseOption) (parseConfig, error) {
+	cfg := config{parse: defaultParseConfig}
+	for i, op := range options {
+		op(&cfg)
+
+		other := cfg
+		other.parse = parseConfig{}
+		if !reflect.DeepEqual(other, config{}) {
+			return parseConfig{}, fmt.Errorf("option %d: %w", i+1, ErrNotParseOption)
+		}
+	}
+	return cfg.parse, nil
+}
+
+// Parse reads the variables of dotenv content from r, exactly like the variables of a file are read, e.g. to parse
+// dotenv text kept in memory. The content is parsed in the Dotenv format, unless the Format option is used.
+// The process environment is not changed.
+//
+//		envMap, err := godotenv.Parse(r, godotenv.Dialect(godotenv.ComposeDialect))
+func Parse(r io.Reader, options ...ParseOption) (map[string]string, error) {
+	p, err := parseConfigOf(options)
+	if err != nil {
+		return nil, err
+	}
+	return p.parseFormat(Dotenv, r)
+}
+
+// ParseString reads the variables of dotenv content, like Parse.
+func ParseString(s string, options ...ParseOption) (map[string]string, error) {
+	return Parse(strings.NewReader(s), options...)
+}
+
+// Expand replaces $NAME and ${NAME} in s with the values returned by lookup, the way variables are expanded
+// in unquoted values of dotenv files. Variables not found by lookup expand to an empty string, and \$ is replaced
+// with a literal dollar sign.
+//
+//		value := godotenv.Expand("$HOME/bin", os.LookupEnv)
+func Expand(s string, lookup func(string) (string, bool)) string {
+	var lex lexer
+	return string(lex.expand([]byte(s), func(name []byte) string {
+		value, _ := lookup(string(name))
+		return value
+	}))
+}
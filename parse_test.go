@@ -0,0 +1,269 @@
+package godotenv
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseMatchesFiles(t *testing.T) {
+	paths, err := filepath.Glob("fixtures/*.env")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range paths {
+		expected, expectedErr := read([]Source{Files(path)})
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		envMap, err := ParseString(string(content))
+
+		if (err != nil) != (expectedErr != nil) || err != nil && !strings.HasSuffix(expectedErr.Error(), err.Error()) {
+			t.Errorf("%s: Parse returned error %v, reading the file returned %v.", path, err, expectedErr)
+			continue
+		}
+		if err == nil && !reflect.DeepEqual(envMap, expected) {
+			t.Errorf("%s: Parse got the variables wrong: expected %v, got %v.", path, expected, envMap)
+		}
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		options  []ParseOption
+		expected map[string]string
+	}{
+		{
+			name:     "default",
+			content:  "A=1\nB=\"$A 2\" # comment\n",
+			expected: map[string]string{"A": "1", "B": "1 2"},
+		},
+		{
+			name:     "format",
+			content:  `{"A": {"B": 1}}`,
+			options:  []ParseOption{Format(JSON), KeySeparator("__")},
+			expected: map[string]string{"A__B": "1"},
+		},
+		{
+			name:     "dialect",
+			content:  "A=${UNDEFINED:-default}\n",
+			options:  []ParseOption{Dialect(ComposeDialect), WithEnviron(nil)},
+			expected: map[string]string{"A": "default"},
+		},
+		{
+			name:     "comments after whitespace",
+			content:  "URL=https://example.com/#fragment\n",
+			options:  []ParseOption{CommentsAfterWhitespace()},
+			expected: map[string]string{"URL": "https://example.com/#fragment"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envMap, err := ParseString(tt.content, tt.options...)
+			if err != nil {
+				t.Fatalf("ParseString returned error: %v.", err)
+			}
+			compareEnvMaps(t, tt.expected, envMap)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		options []ParseOption
+		err     string
+	}{
+		{name: "invalid line", content: "A=1\nB\n", err: "line 2: can't separate key from value"},
+		{name: "strict escapes", content: `A="\a"`, options: []ParseOption{StrictEscapes()}, err: `line 1: value of A: invalid escape sequence "\\a"`},
+		{name: "line size", content: "A=12345\n", options: []ParseOption{MaxLineSize(4)}, err: "line 1: value of A: line exceeds the maximum size of 4 bytes"},
+		{name: "file size", content: `{"A": "12345"}`, options: []ParseOption{Format(JSON), MaxFileSize(4)}, err: "file exceeds the maximum size of 4 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseString(tt.content, tt.options...)
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("Expected error %q, got %v.", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestParseRejectsOtherOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options []ParseOption
+	}{
+		{name: "source", options: []ParseOption{From("fixtures/plain.env")}},
+		{name: "required", options: []ParseOption{StrictEscapes(), Required("A")}},
+		{name: "defaults", options: []ParseOption{Defaults(map[string]string{"A": "1"})}},
+		{name: "alias", options: []ParseOption{Alias("A", "B")}},
+		{name: "prefix", options: []ParseOption{WithPrefix("APP_")}},
+		{name: "system first", options: []ParseOption{PrioritizeSystem()}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if envMap, err := ParseString("A=1\n", tt.options...); !errors.Is(err, ErrNotParseOption) {
+				t.Errorf("Expected ErrNotParseOption, got %v and %v.", envMap, err)
+			}
+
+			scanner := NewScanner(strings.NewReader("A=1\n"), tt.options...)
+			if scanner.Scan() || !errors.Is(scanner.Err(), ErrNotParseOption) {
+				t.Errorf("Expected Scanner to fail with ErrNotParseOption, got %v.", scanner.Err())
+			}
+		})
+	}
+}
+
+// TestOptionsOfParsing pins which options Parse and NewScanner accept, since both rely on options that affect parsing
+// changing nothing but cfg.parse. Every exported option has to be listed.
+func TestOptionsOfParsing(t *testing.T) {
+	tests := map[string]struct {
+		option  ParseOption
+		parse   bool
+		scanner bool
+	}{
+		"Alias":                   {option: Alias("A", "B")},
+		"OnDeprecated":            {option: OnDeprecated(func(string, string) {})},
+		"Dialect":                 {option: Dialect(ComposeDialect), parse: true},
+		"CommentsAfterWhitespace": {option: CommentsAfterWhitespace(), parse: true, scanner: true},
+		"WithEnviron":             {option: WithEnviron([]string{"A=1"}), parse: true},
+		"WithLookup":              {option: WithLookup(os.LookupEnv), parse: true},
+		"ContinueOnError":         {option: ContinueOnError(), parse: true, scanner: true},
+		"StrictEscapes":           {option: StrictEscapes(), parse: true, scanner: true},
+		"Format":                  {option: Format(YAML), parse: true},
+		"KeySeparator":            {option: KeySeparator("."), parse: true},
+		"ListSeparator":           {option: ListSeparator(";"), parse: true},
+		"Variables":               {option: Variables("A")},
+		"WithPrefix":              {option: WithPrefix("APP_")},
+		"StripPrefix":             {option: StripPrefix()},
+		"EmptyValues":             {option: EmptyValues(EmptyAsUnset)},
+		"Defaults":                {option: Defaults(map[string]string{"A": "1"})},
+		"DefaultsFrom":            {option: DefaultsFrom("fixtures/plain.env")},
+		"PrioritizeSystem":        {option: PrioritizeSystem()},
+		"From":                    {option: From("fixtures/plain.env")},
+		"FromReader":              {option: FromReader("reader", strings.NewReader("A=1"))},
+		"FromFS":                  {option: FromFS(os.DirFS("fixtures"), "*.env")},
+		"FromURL":                 {option: FromURL("http://localhost/.env")},
+		"MaxLineSize":             {option: MaxLineSize(1024), parse: true, scanner: true},
+		"MaxFileSize":             {option: MaxFileSize(1024), parse: true, scanner: true},
+		"Required":                {option: Required("A")},
+		"Optional":                {option: Optional("A")},
+		"Schema":                  {option: Schema(map[string]string{"A": "a"})},
+		"SchemaFrom":              {option: SchemaFrom("fixtures/plain.env")},
+		"Match":                   {option: Match("A*")},
+		"MatchRegexp":             {option: MatchRegexp(regexp.MustCompile("^A"))},
+		"RequireMatch":            {option: RequireMatch("A*")},
+		"RequireMatchRegexp":      {option: RequireMatchRegexp(regexp.MustCompile("^A"))},
+		"Order":                   {option: Order(Map(nil))},
+	}
+
+	for _, name := range exportedOptions(t) {
+		if _, ok := tests[name]; !ok {
+			t.Errorf("Option %s is not listed.", name)
+		}
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseString("A=1\n", tt.option)
+			if tt.parse && errors.Is(err, ErrNotParseOption) || !tt.parse && !errors.Is(err, ErrNotParseOption) {
+				t.Errorf("Parse got %s wrong: %v.", name, err)
+			}
+
+			scanner := NewScanner(strings.NewReader("A=1\n"), tt.option)
+			scanner.Scan()
+			err = scanner.Err()
+			switch {
+			case tt.scanner && err != nil:
+				t.Errorf("Scanner returned error for %s: %v.", name, err)
+			case !tt.scanner && tt.parse && !errors.Is(err, ErrNotScannerOption):
+				t.Errorf("Expected Scanner to fail with ErrNotScannerOption for %s, got %v.", name, err)
+			case !tt.parse && !errors.Is(err, ErrNotParseOption):
+				t.Errorf("Expected Scanner to fail with ErrNotParseOption for %s, got %v.", name, err)
+			}
+		})
+	}
+}
+
+// exportedOptions returns the names of the exported functions of the package that return an Option.
+func exportedOptions(t *testing.T) []string {
+	files, err := filepath.Glob("*.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv != nil || !fn.Name.IsExported() || fn.Type.Results == nil || len(fn.Type.Results.List) != 1 {
+				continue
+			}
+			if result, ok := fn.Type.Results.List[0].Type.(*ast.Ident); ok && (result.Name == "Option" || result.Name == "ParseOption") {
+				names = append(names, fn.Name.Name)
+			}
+		}
+	}
+	return names
+}
+
+func TestExpand(t *testing.T) {
+	lookup := func(name string) (string, bool) {
+		value, ok := map[string]string{"A": "1", "B_2": "two", "EMPTY": ""}[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "plain", expected: "plain"},
+		{input: "$A", expected: "1"},
+		{input: "${A}/${B_2}", expected: "1/two"},
+		{input: "$A$B_2", expected: "1two"},
+		{input: "[$EMPTY][$UNDEFINED]", expected: "[][]"},
+		{input: `\$A`, expected: "$A"},
+		{input: `\n$A`, expected: `\n1`},
+		{input: "$ and $lower", expected: "$ and $lower"},
+		{input: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		if value := Expand(tt.input, lookup); value != tt.expected {
+			t.Errorf("Expand(%q) got the value wrong: expected %q, got %q.", tt.input, tt.expected, value)
+		}
+
+		envMap, err := ParseString("A=1\nB_2=two\nEMPTY=\nVALUE=" + tt.input)
+		if err != nil {
+			t.Fatalf("ParseString returned error: %v.", err)
+		}
+		if value := Expand(tt.input, lookup); value != envMap["VALUE"] {
+			t.Errorf("Expand(%q) returned %q, a value in a file is %q.", tt.input, value, envMap["VALUE"])
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package godotenv
+
+import (
+	"regexp"
+	"strings"
+)
+
+var (
+	pythonMultilineWhitespace = regexp.MustCompile(`^\s*`)
+	pythonWhitespace          = regexp.MustCompile(`^[^\S\r\n]*`)
+	pythonExport              = regexp.MustCompile(`^(?:export[^\S\r\n]+)?`)
+	pythonSingleQuotedKey     = regexp.MustCompile(`^'([^']+)'`)
+	pythonUnquotedKey         = regexp.MustCompile(`^([^=#\s]+)`)
+	pythonEqualSign           = regexp.MustCompile(`^(=[^\S\r\n]*)`)
+	pythonSingleQuotedValue   = regexp.MustCompile(`^'((?:\\'|[^'])*)'`)
+	pythonDoubleQuotedValue   = regexp.MustCompile(`^"((?:\\"|[^"])*)"`)
+	pythonUnquotedValue       = regexp.MustCompile(`^([^\r\n]*)`)
+	pythonComment             = regexp.MustCompile(`^(?:[^\S\r\n]*#[^\r\n]*)?`)
+	pythonEndOfLine           = regexp.MustCompile(`^[^\S\r\n]*(?:\r\n|\n|\r|$)`)
+	pythonRestOfLine          = regexp.MustCompile(`^[^\r\n]*(?:\r\n|\r|\n)?`)
+	pythonInlineComment       = regexp.MustCompile(`\s+#.*`)
+	pythonDoubleQuoteEscapes  = regexp.MustCompile(`\\[\\'"abfnrtv]`)
+	pythonSingleQuoteEscapes  = regexp.MustCompile(`\\[\\']`)
+	pythonVariable            = regexp.MustCompile(`\$\{([^}:]*)(?::-([^}]*))?\}`)
+)
+
+// parsePython parses src the way python-dotenv does (with interpolation enabled, as by default).
+//
+// Lines that can't be parsed and keys without values are skipped. Only ${VAR} and ${VAR:-default} forms
+// are expanded, in quoted and unquoted values alike.
+func parsePython(src string, lookup func(string) (string, bool)) map[string]string {
+	envMap := make(map[string]string)
+
+	r := pythonReader{src: src}
+	for r.pos < len(r.src) {
+		key, value, ok := r.binding()
+		if !ok {
+			continue
+		}
+
+		envMap[key] = pythonVariable.ReplaceAllStringFunc(value, func(match string) string {
+			submatch := pythonVariable.FindStringSubmatch(match)
+			if v, ok := envMap[submatch[1]]; ok {
+				return v
+			}
+			if v, ok := lookup(submatch[1]); ok {
+				return v
+			}
+			return submatch[2]
+		})
+	}
+
+	return envMap
+}
+
+type pythonReader struct {
+	src string
+	pos int
+}
+
+// read matches re at the current position, returning the first group, if any.
+func (r *pythonReader) read(re *regexp.Regexp) (string, bool) {
+	match := re.FindStringSubmatch(r.src[r.pos:])
+	if match == nil {
+		return "", false
+	}
+	r.pos += len(match[0])
+	if len(match) > 1 {
+		return match[1], true
+	}
+	return "", true
+}
+
+func (r *pythonReader) peek() byte {
+	if r.pos >= len(r.src) {
+		return 0
+	}
+	return r.src[r.pos]
+}
+
+// binding reads the next statement. On error, the rest of the line is skipped.
+func (r *pythonReader) binding() (key, value string, ok bool) {
+	r.read(pythonMultilineWhitespace)
+	if r.pos >= len(r.src) {
+		return "", "", false
+	}
+	r.read(pythonExport)
+
+	key, hasKey, ok := r.key()
+	if !ok {
+		r.read(pythonRestOfLine)
+		return "", "", false
+	}
+
+	r.read(pythonWhitespace)
+	hasValue := false
+	if r.peek() == '=' {
+		r.read(pythonEqualSign)
+		if value, ok = r.value(); !ok {
+			r.read(pythonRestOfLine)
+			return "", "", false
+		}
+		hasValue = true
+	}
+
+	r.read(pythonComment)
+	if _, ok := r.read(pythonEndOfLine); !ok {
+		r.read(pythonRestOfLine)
+		return "", "", false
+	}
+
+	return key, value, hasKey && hasValue
+}
+
+// key reads a key; a comment has no key.
+func (r *pythonReader) key() (key string, hasKey, ok bool) {
+	switch r.peek() {
+	case '#':
+		return "", false, true
+	case '\'':
+		key, ok = r.read(pythonSingleQuotedKey)
+	default:
+		key, ok = r.read(pythonUnquotedKey)
+	}
+	return key, ok, ok
+}
+
+func (r *pythonReader) value() (string, bool) {
+	switch r.peek() {
+	case '\'':
+		value, ok := r.read(pythonSingleQuotedValue)
+		return decodePythonEscapes(pythonSingleQuoteEscapes, value), ok
+	case '"':
+		value, ok := r.read(pythonDoubleQuotedValue)
+		return decodePythonEscapes(pythonDoubleQuoteEscapes, value), ok
+	case 0, '\n', '\r':
+		return "", true
+	default:
+		value, _ := r.read(pythonUnquotedValue)
+		return strings.TrimRight(pythonInlineComment.ReplaceAllString(value, ""), " \t\n\r\f\v"), true
+	}
+}
+
+func decodePythonEscapes(re *regexp.Regexp, value string) string {
+	return re.ReplaceAllStringFunc(value, func(match string) string {
+		switch match[1] {
+		case 'a':
+			return "\a"
+		case 'b':
+			return "\b"
+		case 'f':
+			return "\f"
+		case 'n':
+			return "\n"
+		case 'r':
+			return "\r"
+		case 't':
+			return "\t"
+		case 'v':
+			return "\v"
+		default:
+			return match[1:]
+		}
+	})
+}
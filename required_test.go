@@ -0,0 +1,70 @@
+package godotenv
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+)
+
+func TestRequired(t *testing.T) {
+	envMap, notFoundVars, err := Load(Required("OPTION_A", "DB_URL", "PORT"), Optional("OPTION_B", "OPTIONAL"), From("fixtures/plain.env"))
+	compareEnvMaps(t, map[string]string{"OPTION_A": "1", "OPTION_B": "2"}, envMap)
+	if strings.Join(notFoundVars, ",") != "DB_URL,PORT" {
+		t.Errorf("Expected only required variables to be reported as not found, got %+v.", notFoundVars)
+	}
+
+	var missing *MissingVariablesError
+	if !errors.As(err, &missing) {
+		t.Fatalf("Expected *MissingVariablesError, got %v.", err)
+	}
+	if strings.Join(missing.Variables, ",") != "DB_URL,PORT" {
+		t.Errorf("Expected DB_URL and PORT to be missing, got %+v.", missing.Variables)
+	}
+	if err.Error() != "missing required variables: DB_URL, PORT" {
+		t.Errorf("Unexpected error message: %q.", err)
+	}
+}
+
+func TestRequiredWithSchema(t *testing.T) {
+	_, _, err := Load(
+		Required("DB_URL", "PORT", "TOKEN", "USER"),
+		From("fixtures/plain.env"),
+		SchemaFrom("fixtures/example.env"),
+		Schema(map[string]string{"USER": "name of the service account"}),
+	)
+
+	var missing *MissingVariablesError
+	if !errors.As(err, &missing) {
+		t.Fatalf("Expected *MissingVariablesError, got %v.", err)
+	}
+
+	expectedHints := map[string]string{
+		"DB_URL": "Connection string of the main database. Only PostgreSQL is supported.",
+		"PORT":   "e.g. 8080",
+		"USER":   "name of the service account",
+	}
+	compareEnvMaps(t, expectedHints, missing.Hints)
+	if !strings.Contains(err.Error(), "PORT (e.g. 8080), TOKEN, USER (name of the service account)") {
+		t.Errorf("Expected hints in the error message, got %q.", err)
+	}
+}
+
+func TestRequiredKeepsSourceError(t *testing.T) {
+	_, _, err := Load(Required("OPTION_A"), From("fixtures/missing.env"))
+
+	var missing *MissingVariablesError
+	if !errors.As(err, &missing) {
+		t.Fatalf("Expected *MissingVariablesError, got %v.", err)
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected the error to wrap the error of the missing file, got %v.", err)
+	}
+}
+
+func TestRequiredFound(t *testing.T) {
+	_, notFoundVars, err := Load(Required("A"), From("fixtures/plain.env"), WithPrefix("OPTION_"), StripPrefix(), Required("B"))
+	if err != nil || len(notFoundVars) != 0 {
+		t.Errorf("Expected required variables to be found, got %v, %+v.", err, notFoundVars)
+	}
+}
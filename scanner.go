@@ -0,0 +1,238 @@
+package godotenv
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+)
+
+// Scanner reads entries of a dotenv file one at a time, so large files and piped input can be processed
+// without loading all variables first. Only the default dialect is supported.
+//
+//		scanner := godotenv.NewScanner(r)
+//		for scanner.Scan() {
+//			entry := scanner.Entry()
+//			...
+//		}
+//		if err := scanner.Err(); err != nil {
+//			...
+//		}
+//
+// Variables are expanded with values of entries scanned before.
+type Scanner struct {
+	r               io.Reader
+	lines           *lineReader
+	maxLineSize     int
+	maxFileSize     int64
+	continueOnError bool
+	lex             lexer
+	// buf holds an entry that spans several lines.
+	buf       []byte
+	variables map[string]string
+	entry     Entry
+	line      int
+	// entryErr is the first error of the entry being scanned, which is skipped up to its end with ContinueOnError.
+	entryErr error
+	// errs are the errors of skipped entries.
+	errs []error
+	err  error
+}
+
+// ErrNotScannerOption is wrapped by the error returned when a parsing option that the Scanner doesn't support,
+// such as Format or Dialect, is given to NewScanner or Entries.
+var ErrNotScannerOption = errors.New("option isn't supported by Scanner")
+
+// NewScanner returns a Scanner reading from r. The options MaxLineSize, MaxFileSize, CommentsAfterWhitespace,
+// StrictEscapes and ContinueOnError are supported. If another parsing option is given, Scan returns false, and Err
+// returns an error wrapping ErrNotScannerOption. If an option doesn't affect parsing, the error wraps
+// ErrNotParseOption instead.
+func NewScanner(r io.Reader, options ...ParseOption) *Scanner {
+	p, err := scannerConfigOf(options)
+	s := newScanner(r, p)
+	s.err = err
+	return s
+}
+
+// scannerConfigOf returns the parsing settings of the options. It fails if an option changes settings
+// the Scanner doesn't use.
+func scannerConfigOf(options []ParseOption) (parseConfig, error) {
+	p, err := parseConfigOf(options)
+	if err != nil {
+		return parseConfig{}, err
+	}
+
+	for i, op := range options {
+		var cfg config
+		op(&cfg)
+
+		other := cfg.parse
+		other.maxLineSize, other.maxFileSize = 0, 0
+		other.commentsAfterWhitespace, other.strictEscapes, other.continueOnError = false, false, false
+		if !reflect.DeepEqual(other, parseConfig{}) {
+			return parseConfig{}, fmt.Errorf("option %d: %w", i+1, ErrNotScannerOption)
+		}
+	}
+	return p, nil
+}
+
+func newScanner(r io.Reader, p parseConfig) *Scanner {
+	return &Scanner{
+		r:               r,
+		maxLineSize:     p.maxLineSize,
+		maxFileSize:     p.maxFileSize,
+		continueOnError: p.continueOnError,
+		lex:             lexer{commentsAfterWhitespace: p.commentsAfterWhitespace, strictEscapes: p.strictEscapes},
+		variables:       make(map[string]string),
+	}
+}
+
+// Scan advances the Scanner to the next entry, which is then available through the Entry method.
+// It returns false when the input ends or an error occurs. With ContinueOnError, invalid entries are skipped.
+func (s *Scanner) Scan() bool {
+	if s.err != nil {
+		return false
+	}
+	if s.lines == nil {
+		s.lines = &lineReader{r: bufio.NewReader(newDecoder(limitSize(s.r, s.maxFileSize))), maxLineSize: s.maxLineSize}
+	}
+
+	for {
+		s.line++
+		line, err := s.lines.readLine()
+		if err == io.EOF {
+			s.err = errors.Join(s.errs...)
+			return false
+		}
+		if err == nil {
+			err = s.checkEncoding(line)
+		}
+		if err != nil {
+			line, err = s.recoverLine(line, s.readError(keyOf(line), err))
+			if !s.continueOnError || !recoverable(err) {
+				s.skip(err)
+				return false
+			}
+		} else if isIgnoredLine(line) {
+			continue
+		}
+
+		start := s.line
+		entry, err := s.scanEntry(line, err)
+		if err != nil {
+			if s.skip(err) {
+				continue
+			}
+			return false
+		}
+
+		entry.Line = start
+		s.variables[entry.Key] = entry.Value
+		s.entry = entry
+		return true
+	}
+}
+
+// skip reports whether scanning continues after the error, which it does with ContinueOnError if the error
+// concerns a single entry. Otherwise, the error is the one returned by Err, joined with the errors of skipped entries.
+func (s *Scanner) skip(err error) bool {
+	if !s.continueOnError {
+		s.err = err
+		return false
+	}
+
+	s.errs = append(s.errs, err)
+	if recoverable(err) {
+		return true
+	}
+	s.err = errors.Join(s.errs...)
+	return false
+}
+
+// recoverLine returns the line that could not be read because of the error, in full, so with ContinueOnError the entry
+// can be skipped up to its end. It returns the error of the line, or an error that stops the scanning.
+func (s *Scanner) recoverLine(line []byte, err error) ([]byte, error) {
+	if !s.continueOnError || !recoverable(err) {
+		return nil, err
+	}
+
+	line, readErr := s.lines.readRest(line)
+	if readErr != nil {
+		return nil, s.readError(nil, readErr)
+	}
+	return line, err
+}
+
+// recoverable reports whether the error concerns a single entry, so the entry can be skipped with ContinueOnError.
+func recoverable(err error) bool {
+	var parseErr *ParseError
+	return errors.As(err, &parseErr) && !errors.Is(err, ErrFileTooLarge)
+}
+
+// readError returns the error for a line that could not be read. If a limit is exceeded, the error names the line
+// and the variable with the given key, if it is known.
+func (s *Scanner) readError(key []byte, err error) error {
+	if !errors.Is(err, ErrLineTooLong) && !errors.Is(err, ErrFileTooLarge) {
+		return err
+	}
+
+	if len(key) > 0 {
+		err = fmt.Errorf("value of %s: %w", key, err)
+	}
+	return &ParseError{Line: s.line, Err: err}
+}
+
+// checkEncoding returns an error naming the line and the column if the line is not valid UTF-8.
+func (s *Scanner) checkEncoding(line []byte) error {
+	if column := invalidUTF8(line); column > 0 {
+		return &ParseError{Line: s.line, Err: fmt.Errorf("invalid UTF-8 at column %d", column)}
+	}
+	return nil
+}
+
+// keyOf returns the key of a line of the form key=value, or nil if there is no separator on the line.
+func keyOf(line []byte) []byte {
+	if separator := bytes.IndexByte(line, '='); separator >= 0 {
+		return exportedKey(line[:separator])
+	}
+	return nil
+}
+
+// Entry returns the entry found by the last call to Scan.
+func (s *Scanner) Entry() Entry {
+	return s.entry
+}
+
+// Err returns the first error encountered by the Scanner. With ContinueOnError, it returns the errors of all
+// skipped entries joined with errors.Join, once Scan has returned false.
+func (s *Scanner) Err() error {
+	return s.err
+}
+
+// Entries returns an iterator over the entries of the dotenv content read from r, with the same options
+// as NewScanner. If an error occurs, it is yielded with an empty entry, and the iteration stops; with ContinueOnError,
+// the errors of skipped entries are yielded at the end.
+//
+//		for entry, err := range godotenv.Entries(r) {
+//			if err != nil {
+//				...
+//			}
+//			...
+//		}
+func Entries(r io.Reader, options ...ParseOption) iter.Seq2[Entry, error] {
+	return func(yield func(Entry, error) bool) {
+		s := NewScanner(r, options...)
+		for s.Scan() {
+			if !yield(s.Entry(), nil) {
+				return
+			}
+		}
+
+		if err := s.Err(); err != nil {
+			yield(Entry{}, err)
+		}
+	}
+}
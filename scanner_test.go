@@ -0,0 +1,121 @@
+package godotenv
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScanner(t *testing.T) {
+	content := `# Settings
+OPTION_A=1
+export OPTION_B="$OPTION_A 2" # the second option
+
+OPTION_C='$OPTION_B'
+`
+	expected := []Entry{
+		{Key: "OPTION_A", Value: "1", RawValue: "1", Line: 2},
+		{Key: "OPTION_B", Value: "1 2", RawValue: `"$OPTION_A 2"`, Line: 3, Comment: "the second option"},
+		{Key: "OPTION_C", Value: "$OPTION_B", RawValue: "'$OPTION_B'", Line: 5},
+	}
+
+	var entries []Entry
+	scanner := NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		entries = append(entries, scanner.Entry())
+	}
+
+	if err := scanner.Err(); err != nil {
+		t.Fatalf("Scanner returned error: %v.", err)
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("Scanner got the entries wrong: expected %+v, got %+v.", expected, entries)
+	}
+}
+
+func TestScannerError(t *testing.T) {
+	scanner := NewScanner(strings.NewReader("OPTION_A=1\n\nOPTION_B\nOPTION_C=3\n"))
+	for scanner.Scan() {
+		if entry := scanner.Entry(); entry.Key != "OPTION_A" {
+			t.Errorf("Scanner returned %s after an error.", entry.Key)
+		}
+	}
+
+	err := scanner.Err()
+	if err == nil || !strings.HasPrefix(err.Error(), "line 3: ") {
+		t.Errorf("Expected an error on line 3, got %v.", err)
+	}
+	if scanner.Scan() {
+		t.Error("Scanner continued after an error.")
+	}
+}
+
+func TestEntries(t *testing.T) {
+	var keys []string
+	for entry, err := range Entries(strings.NewReader("OPTION_A=1\nOPTION_B=2\nOPTION_C\n")) {
+		if err != nil {
+			if len(keys) != 2 || !strings.HasPrefix(err.Error(), "line 3: ") {
+				t.Errorf("Unexpected error after %v: %v.", keys, err)
+			}
+			continue
+		}
+		keys = append(keys, entry.Key)
+	}
+	if !reflect.DeepEqual(keys, []string{"OPTION_A", "OPTION_B"}) {
+		t.Errorf("Entries got the keys wrong: %v.", keys)
+	}
+
+	keys = nil
+	for entry := range Entries(strings.NewReader("OPTION_A=1\nOPTION_B=2\n")) {
+		keys = append(keys, entry.Key)
+		break
+	}
+	if !reflect.DeepEqual(keys, []string{"OPTION_A"}) {
+		t.Errorf("Entries didn't stop after break: %v.", keys)
+	}
+}
+
+func TestScannerOptions(t *testing.T) {
+	scanner := NewScanner(strings.NewReader("URL=https://example.com/#fragment\nESCAPE=\"\\a\"\n"), CommentsAfterWhitespace(), StrictEscapes())
+	if !scanner.Scan() {
+		t.Fatalf("Scanner returned error: %v.", scanner.Err())
+	}
+	if entry := scanner.Entry(); entry.Value != "https://example.com/#fragment" {
+		t.Errorf("Scanner got the value of %s wrong: %q.", entry.Key, entry.Value)
+	}
+
+	if scanner.Scan() {
+		t.Errorf("Expected an error for an invalid escape sequence, got %+v.", scanner.Entry())
+	}
+	if err := scanner.Err(); err == nil || !strings.HasPrefix(err.Error(), "line 2: ") {
+		t.Errorf("Expected an error on line 2, got %v.", err)
+	}
+}
+
+func TestScannerLimits(t *testing.T) {
+	tests := []struct {
+		name        string
+		option      ParseOption
+		target      error
+		expectedErr string
+	}{
+		{"line size", MaxLineSize(18), ErrLineTooLong, "line 2: value of CERTIFICATE: line exceeds the maximum size of 18 bytes"},
+		{"file size", MaxFileSize(30), ErrFileTooLarge, "line 2: value of CERTIFICATE: file exceeds the maximum size of 30 bytes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scanner := NewScanner(strings.NewReader("OPTION_A=1\nexport CERTIFICATE=1234\n"), tt.option)
+			if !scanner.Scan() || scanner.Entry().Key != "OPTION_A" {
+				t.Fatalf("Scanner got the first entry wrong: %+v, %v.", scanner.Entry(), scanner.Err())
+			}
+			if scanner.Scan() {
+				t.Errorf("Expected an error for the second entry, got %+v.", scanner.Entry())
+			}
+			if err := scanner.Err(); !errors.Is(err, tt.target) || err.Error() != tt.expectedErr {
+				t.Errorf("Expected error %q, got %v.", tt.expectedErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package godotenv
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+)
+
+// selector selects variables by a pattern.
+type selector struct {
+	pattern  string
+	match    func(name string) bool
+	required bool
+	// err is set if the pattern is malformed.
+	err error
+}
+
+// Match specifies glob patterns of variables Get... functions should look for, e.g. "AWS_*".
+// The pattern syntax is the one of path.Match.
+//
+// It can be combined with the Variables option and other patterns; variables that match any of them are acquired.
+// With the WithPrefix option, patterns are matched against names without the prefix.
+func Match(patterns ...string) Option {
+	return func(cfg *config) {
+		cfg.selectors = append(cfg.selectors, globSelectors(patterns, false)...)
+	}
+}
+
+// MatchRegexp works like Match, but selects variables with names matching any of the regular expressions.
+func MatchRegexp(expressions ...*regexp.Regexp) Option {
+	return func(cfg *config) {
+		cfg.selectors = append(cfg.selectors, regexpSelectors(expressions, false)...)
+	}
+}
+
+// RequireMatch works like Match, but if nothing matches one of the patterns,
+// the pattern is reported as a not found variable and Load returns an error.
+func RequireMatch(patterns ...string) Option {
+	return func(cfg *config) {
+		cfg.selectors = append(cfg.selectors, globSelectors(patterns, true)...)
+	}
+}
+
+// RequireMatchRegexp works like MatchRegexp, but if nothing matches one of the regular expressions,
+// the expression is reported as a not found variable and Load returns an error.
+func RequireMatchRegexp(expressions ...*regexp.Regexp) Option {
+	return func(cfg *config) {
+		cfg.selectors = append(cfg.selectors, regexpSelectors(expressions, true)...)
+	}
+}
+
+func globSelectors(patterns []string, required bool) []selector {
+	selectors := make([]selector, 0, len(patterns))
+	for _, pattern := range patterns {
+		pattern := pattern
+		_, err := path.Match(pattern, "")
+		selectors = append(selectors, selector{
+			pattern: pattern,
+			match: func(name string) bool {
+				matched, _ := path.Match(pattern, name)
+				return matched
+			},
+			required: required,
+			err:      err,
+		})
+	}
+	return selectors
+}
+
+func regexpSelectors(expressions []*regexp.Regexp, required bool) []selector {
+	selectors := make([]selector, 0, len(expressions))
+	for _, re := range expressions {
+		selectors = append(selectors, selector{pattern: re.String(), match: re.MatchString, required: required})
+	}
+	return selectors
+}
+
+// selectMatching returns names of variables matching the selectors,
+// required patterns that match nothing and the first error encountered.
+func (cfg config) selectMatching(variables map[string]string) (matched []string, notMatched []string, err error) {
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	selected := make(map[string]bool)
+	for _, s := range cfg.selectors {
+		if s.err != nil {
+			if err == nil {
+				err = fmt.Errorf("invalid pattern %q: %w", s.pattern, s.err)
+			}
+			continue
+		}
+
+		found := false
+		for _, name := range names {
+			if cfg.resultName(name) == "" || !s.match(name) {
+				continue
+			}
+
+			found = true
+			if !selected[name] {
+				selected[name] = true
+				matched = append(matched, name)
+			}
+		}
+
+		if s.required && !found {
+			notMatched = append(notMatched, cfg.resultName(s.pattern))
+			if err == nil {
+				err = fmt.Errorf("no variables match %q", cfg.resultName(s.pattern))
+			}
+		}
+	}
+
+	return matched, notMatched, err
+}
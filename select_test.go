@@ -0,0 +1,72 @@
+package godotenv
+
+import (
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGetMatch(t *testing.T) {
+	envMap, notFoundVars, err := Load(Match("BILLING_DB_*", "BILLING_T*"), Variables("SEARCH_DB_URL"), From("fixtures/prefixed.env"))
+	if err != nil || len(notFoundVars) != 0 {
+		t.Fatalf("Unexpected result: %v, not found: %+v.", err, notFoundVars)
+	}
+	compareEnvMaps(t, map[string]string{
+		"BILLING_DB_URL": "postgres://billing",
+		"BILLING_TOKEN":  "secret",
+		"SEARCH_DB_URL":  "postgres://search",
+	}, envMap)
+}
+
+func TestGetMatchRegexp(t *testing.T) {
+	envMap, _ := Get(MatchRegexp(regexp.MustCompile(`^[A-Z]+_DB_URL$`)), From("fixtures/prefixed.env"))
+	compareEnvMaps(t, map[string]string{
+		"BILLING_DB_URL": "postgres://billing",
+		"SEARCH_DB_URL":  "postgres://search",
+	}, envMap)
+}
+
+func TestGetMatchSystem(t *testing.T) {
+	err := os.Setenv("GODOTENV_MATCH_SYSTEM", "system")
+	if err != nil {
+		t.Fatalf("Error setting environment variable: %v.", err)
+	}
+	defer os.Unsetenv("GODOTENV_MATCH_SYSTEM")
+
+	envMap, _ := Get(Match("GODOTENV_MATCH_*"), From("fixtures/prefixed.env"))
+	compareEnvMaps(t, map[string]string{"GODOTENV_MATCH_SYSTEM": "system"}, envMap)
+}
+
+func TestGetMatchWithPrefix(t *testing.T) {
+	envMap, _ := Get(Match("DB_*"), WithPrefix("BILLING_"), StripPrefix(), From("fixtures/prefixed.env"))
+	compareEnvMaps(t, map[string]string{"DB_URL": "postgres://billing"}, envMap)
+
+	envMap, _ = Get(Match("*"), WithPrefix("BILLING_"), StripPrefix(), From("fixtures/prefixed.env"))
+	compareEnvMaps(t, map[string]string{"DB_URL": "postgres://billing", "TOKEN": "secret"}, envMap)
+}
+
+func TestGetRequireMatch(t *testing.T) {
+	envMap, notFoundVars, err := Load(
+		RequireMatch("BILLING_*", "GODOTENV_MISSING_*"),
+		RequireMatchRegexp(regexp.MustCompile(`^GODOTENV_MISSING_`)),
+		Match("GODOTENV_OPTIONAL_*"),
+		From("fixtures/prefixed.env"),
+	)
+	if len(envMap) != 3 {
+		t.Errorf("Expected variables matching BILLING_* to be acquired, got %+v.", envMap)
+	}
+	if len(notFoundVars) != 2 || notFoundVars[0] != "GODOTENV_MISSING_*" || notFoundVars[1] != "^GODOTENV_MISSING_" {
+		t.Errorf("Expected required patterns to be reported as not found, got %+v.", notFoundVars)
+	}
+	if err == nil || !strings.Contains(err.Error(), `"GODOTENV_MISSING_*"`) {
+		t.Errorf("Expected error about the first required pattern, got %v.", err)
+	}
+}
+
+func TestGetMatchInvalidPattern(t *testing.T) {
+	_, _, err := Load(Match("[A-"), From("fixtures/prefixed.env"))
+	if err == nil || !strings.Contains(err.Error(), "invalid pattern") {
+		t.Errorf("Expected invalid pattern error, got %v.", err)
+	}
+}
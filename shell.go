@@ -0,0 +1,461 @@
+package godotenv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// parseShell parses src the way a POSIX shell reads it with "set -a; . ./.env".
+//
+// Only variable assignments and the export builtin are supported. Other commands, command substitution and
+// arithmetic expansion result in an error, as do the errors of the shell itself, e.g. ${VAR:?} of an unset variable.
+func parseShell(src string, lookup func(string) (string, bool)) (map[string]string, error) {
+	p := shellParser{src: src, line: 1, lookup: lookup, envMap: make(map[string]string)}
+
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == ' ' || c == '\t':
+			p.pos++
+		case c == '\n':
+			p.pos++
+			p.line++
+		case c == '\\' && p.continuation():
+		case c == '#':
+			p.skipComment()
+		case c == ';':
+			return p.envMap, p.errorf("syntax error: unexpected %q", ";")
+		default:
+			if err := p.command(); err != nil {
+				return p.envMap, err
+			}
+		}
+	}
+
+	return p.envMap, nil
+}
+
+type shellParser struct {
+	src    string
+	pos    int
+	line   int
+	lookup func(string) (string, bool)
+	envMap map[string]string
+	// skip is set while reading a word that the shell doesn't expand, e.g. "${SET-${UNSET:?}}",
+	// so its assignments and errors have no effect.
+	skip bool
+}
+
+func (p *shellParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("line %d: "+format, append([]interface{}{p.line}, args...)...)
+}
+
+// get returns the value of a variable assigned earlier in the file or inherited from the environment.
+func (p *shellParser) get(name string) (string, bool) {
+	if value, ok := p.envMap[name]; ok {
+		return value, true
+	}
+	return p.lookup(name)
+}
+
+// continuation skips a backslash-newline pair at the current position, if there is one.
+func (p *shellParser) continuation() bool {
+	if strings.HasPrefix(p.src[p.pos:], "\\\n") {
+		p.pos += 2
+		p.line++
+		return true
+	}
+	return false
+}
+
+func (p *shellParser) skipComment() {
+	if end := strings.IndexByte(p.src[p.pos:], '\n'); end >= 0 {
+		p.pos += end
+	} else {
+		p.pos = len(p.src)
+	}
+}
+
+func (p *shellParser) skipBlanks() {
+	for p.pos < len(p.src) {
+		switch c := p.src[p.pos]; {
+		case c == ' ' || c == '\t':
+			p.pos++
+		case c == '\\' && p.continuation():
+		default:
+			return
+		}
+	}
+}
+
+func isShellWordEnd(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == ';'
+}
+
+func isShellNameStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
+
+// name scans the longest variable name starting at pos. Line continuations inside and after the name
+// are skipped, so the name ends at end, lines later.
+func (p *shellParser) name(pos int) (name string, end, lines int) {
+	var sb strings.Builder
+	for end = pos; end < len(p.src); end++ {
+		c := p.src[end]
+		switch {
+		case strings.HasPrefix(p.src[end:], "\\\n"):
+			end++
+			lines++
+		case isShellNameStart(c) || sb.Len() > 0 && c >= '0' && c <= '9':
+			sb.WriteByte(c)
+		default:
+			return sb.String(), end, lines
+		}
+	}
+	return sb.String(), end, lines
+}
+
+// command reads a simple command: a list of assignments, optionally followed by the export builtin and its arguments.
+func (p *shellParser) command() error {
+	export := false
+
+	for {
+		p.skipBlanks()
+		if p.pos >= len(p.src) {
+			return nil
+		}
+		switch p.src[p.pos] {
+		case '\n':
+			return nil
+		case ';':
+			p.pos++
+			return nil
+		case '#':
+			p.skipComment()
+			return nil
+		}
+
+		name, end, lines := p.name(p.pos)
+		wordEnd := end == len(p.src) || isShellWordEnd(p.src[end])
+		switch {
+		case name != "" && strings.HasPrefix(p.src[end:], "="):
+			p.pos, p.line = end+1, p.line+lines
+			value, err := p.word(true)
+			if err != nil {
+				return err
+			}
+			p.envMap[name] = value
+		case export:
+			if name == "" || !wordEnd {
+				return p.errorf("export: bad variable name %q", p.rawWord())
+			}
+			p.pos, p.line = end, p.line+lines
+			if value, ok := p.get(name); ok {
+				p.envMap[name] = value
+			}
+		case name == "export" && wordEnd:
+			p.pos, p.line = end, p.line+lines
+			export = true
+		default:
+			return p.errorf("unsupported command %q", p.rawWord())
+		}
+	}
+}
+
+// rawWord returns the text of the word at the current position, for error messages.
+func (p *shellParser) rawWord() string {
+	end := strings.IndexFunc(p.src[p.pos:], func(r rune) bool {
+		return r < 0x80 && isShellWordEnd(byte(r))
+	})
+	if end < 0 {
+		return p.src[p.pos:]
+	}
+	return p.src[p.pos : p.pos+end]
+}
+
+// word reads an unquoted word. Assignment values have tildes expanded at the start and after colons.
+func (p *shellParser) word(assignment bool) (string, error) {
+	var sb strings.Builder
+	tilde := assignment
+
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		if tilde && c == '~' {
+			home, err := p.tilde()
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(home)
+			tilde = false
+			continue
+		}
+		tilde = false
+
+		switch c {
+		case ' ', '\t', '\n', ';':
+			return sb.String(), nil
+		case '&', '|', '<', '>', '(', ')':
+			return "", p.errorf("unsupported operator %q", string(c))
+		case '`':
+			return "", p.errorf("command substitution is not supported")
+		case '\\':
+			if p.continuation() {
+				continue
+			}
+			p.pos++
+			if p.pos < len(p.src) {
+				sb.WriteByte(p.src[p.pos])
+				p.pos++
+			} else {
+				sb.WriteByte('\\')
+			}
+		case '\'':
+			value, err := p.singleQuoted()
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(value)
+		case '"':
+			value, err := p.doubleQuoted()
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(value)
+		case '$':
+			value, err := p.expansion(false)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(value)
+		default:
+			tilde = assignment && c == ':'
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	return sb.String(), nil
+}
+
+// tilde expands a tilde prefix at the current position. Only the home directory of the current user is supported.
+func (p *shellParser) tilde() (string, error) {
+	rest := p.src[p.pos+1:]
+	end := strings.IndexFunc(rest, func(r rune) bool {
+		return r == '/' || r == ':' || r < 0x80 && isShellWordEnd(byte(r))
+	})
+	if end < 0 {
+		end = len(rest)
+	}
+
+	login := rest[:end]
+	if strings.ContainsAny(login, "'\"\\$`&|<>()") {
+		p.pos++
+		return "~", nil
+	}
+	if login != "" {
+		return "", p.errorf("tilde expansion of %q is not supported", "~"+login)
+	}
+
+	p.pos++
+	if home, ok := p.get("HOME"); ok {
+		return home, nil
+	}
+	return "~", nil
+}
+
+func (p *shellParser) singleQuoted() (string, error) {
+	end := strings.IndexByte(p.src[p.pos+1:], '\'')
+	if end < 0 {
+		return "", p.errorf("unterminated quoted string")
+	}
+	value := p.src[p.pos+1 : p.pos+1+end]
+	p.line += strings.Count(value, "\n")
+	p.pos += end + 2
+	return value, nil
+}
+
+// doubleQuoted reads a double-quoted string, where a backslash escapes only "$", "`", `"`, "\" and a newline.
+func (p *shellParser) doubleQuoted() (string, error) {
+	var sb strings.Builder
+	start := p.line
+	p.pos++
+
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch c {
+		case '"':
+			p.pos++
+			return sb.String(), nil
+		case '\\':
+			if p.continuation() {
+				continue
+			}
+			if p.pos+1 < len(p.src) && strings.IndexByte("$`\"\\", p.src[p.pos+1]) >= 0 {
+				sb.WriteByte(p.src[p.pos+1])
+				p.pos += 2
+			} else {
+				sb.WriteByte('\\')
+				p.pos++
+			}
+		case '$':
+			value, err := p.expansion(true)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(value)
+		case '`':
+			return "", p.errorf("command substitution is not supported")
+		default:
+			if c == '\n' {
+				p.line++
+			}
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	p.line = start
+	return "", p.errorf("unterminated quoted string")
+}
+
+// expansion expands a parameter at the current position, which holds a dollar sign.
+func (p *shellParser) expansion(quoted bool) (string, error) {
+	p.pos++
+	if p.pos >= len(p.src) {
+		return "$", nil
+	}
+
+	c := p.src[p.pos]
+	switch {
+	case c == '{':
+		return p.braced(quoted)
+	case c == '(':
+		return "", p.errorf("command substitution is not supported")
+	case isShellNameStart(c):
+		name, end, lines := p.name(p.pos)
+		p.pos, p.line = end, p.line+lines
+		value, _ := p.get(name)
+		return value, nil
+	case strings.IndexByte("@*#?-$!0123456789", c) >= 0:
+		return "", p.errorf("special parameter $%c is not supported", c)
+	case !quoted && (c == '\'' || c == '"'):
+		// $'...' and $"..." differ between shells.
+		return "", p.errorf("%s quoting is not supported", "$"+string(c)+"..."+string(c))
+	default:
+		return "$", nil
+	}
+}
+
+// braced expands ${NAME}, ${#NAME} and ${NAME} with one of the "-", "=", "?" and "+" modifiers,
+// with or without a colon.
+func (p *shellParser) braced(quoted bool) (string, error) {
+	p.pos++
+	if strings.HasPrefix(p.src[p.pos:], "#") {
+		if name, end, lines := p.name(p.pos + 1); name != "" && strings.HasPrefix(p.src[end:], "}") {
+			p.pos, p.line = end+1, p.line+lines
+			value, _ := p.get(name)
+			return fmt.Sprint(len([]rune(value))), nil
+		}
+	}
+
+	name, end, lines := p.name(p.pos)
+	if name == "" {
+		return "", p.errorf("unsupported substitution %q", "${"+p.rawWord())
+	}
+	p.pos, p.line = end, p.line+lines
+	value, set := p.get(name)
+
+	if strings.HasPrefix(p.src[p.pos:], "}") {
+		p.pos++
+		return value, nil
+	}
+
+	// With a colon, an empty value is treated the same as an unset one.
+	colon := strings.HasPrefix(p.src[p.pos:], ":")
+	if colon {
+		p.pos++
+	}
+	if p.pos >= len(p.src) || strings.IndexByte("-=?+", p.src[p.pos]) < 0 {
+		return "", p.errorf("unsupported substitution of %s", name)
+	}
+	op := p.src[p.pos]
+	p.pos++
+	set = set && (!colon || value != "")
+
+	skip := p.skip
+	p.skip = skip || set == (op != '+')
+	word, err := p.bracedWord(quoted)
+	p.skip = skip
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case op == '+' && set:
+		return word, nil
+	case op == '+' || set:
+		return value, nil
+	case op == '=' && !p.skip:
+		p.envMap[name] = word
+	case op == '?' && !p.skip:
+		if word == "" {
+			word = "parameter not set"
+		}
+		return "", p.errorf("%s: %s", name, word)
+	}
+	return word, nil
+}
+
+// bracedWord reads the word of a ${NAME:-word} expansion up to the closing brace.
+func (p *shellParser) bracedWord(quoted bool) (string, error) {
+	var sb strings.Builder
+	start := p.line
+
+	for p.pos < len(p.src) {
+		c := p.src[p.pos]
+		switch {
+		case c == '}':
+			p.pos++
+			return sb.String(), nil
+		case c == '\\':
+			if p.continuation() {
+				continue
+			}
+			if p.pos+1 < len(p.src) && (!quoted || strings.IndexByte("$`\"\\}", p.src[p.pos+1]) >= 0) {
+				sb.WriteByte(p.src[p.pos+1])
+				p.pos += 2
+			} else {
+				sb.WriteByte('\\')
+				p.pos++
+			}
+		case c == '\'' && !quoted:
+			value, err := p.singleQuoted()
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(value)
+		case c == '"':
+			value, err := p.doubleQuoted()
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(value)
+		case c == '$':
+			value, err := p.expansion(quoted)
+			if err != nil {
+				return "", err
+			}
+			sb.WriteString(value)
+		case c == '`':
+			return "", p.errorf("command substitution is not supported")
+		default:
+			if c == '\n' {
+				p.line++
+			}
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+
+	p.line = start
+	return "", p.errorf("missing closing brace")
+}
@@ -0,0 +1,147 @@
+package godotenv
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// shellEnviron is the environment the fixtures are sourced in, both by the shell and by the shell dialect.
+var shellEnviron = map[string]string{
+	"HOME":                 "/home/godotenv",
+	"GODOTENV_SHELL_SET":   "set value",
+	"GODOTENV_SHELL_EMPTY": "",
+}
+
+// shellManagedVariables are set by shells themselves.
+var shellManagedVariables = map[string]bool{"PWD": true, "OLDPWD": true, "SHLVL": true, "_": true}
+
+// TestShellHelperProcess isn't a real test: the shell executes it to print its environment.
+func TestShellHelperProcess(t *testing.T) {
+	if os.Getenv("GODOTENV_SHELL_HELPER") != "1" {
+		return
+	}
+
+	envMap := make(map[string]string)
+	for _, variable := range os.Environ() {
+		if kv := strings.SplitN(variable, "=", 2); len(kv) == 2 {
+			envMap[kv[0]] = kv[1]
+		}
+	}
+	delete(envMap, "GODOTENV_SHELL_HELPER")
+
+	_ = json.NewEncoder(os.Stdout).Encode(envMap)
+	os.Exit(0)
+}
+
+// sourceWithShell returns the environment /bin/sh produces with "set -a; . file".
+func sourceWithShell(file string) (map[string]string, error) {
+	file, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("/bin/sh", "-c", `set -ae; . "$1"; exec "$2" -test.run='^TestShellHelperProcess$'`, "sh", file, os.Args[0])
+	cmd.Env = []string{"GODOTENV_SHELL_HELPER=1"}
+	for key, value := range shellEnviron {
+		cmd.Env = append(cmd.Env, key+"="+value)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var envMap map[string]string
+	err = json.Unmarshal(out, &envMap)
+	return envMap, err
+}
+
+func TestShellDialectMatchesShell(t *testing.T) {
+	if _, err := os.Stat("/bin/sh"); err != nil {
+		t.Skip("/bin/sh is not available.")
+	}
+
+	// Fixtures named invalid*.env are expected to fail, others are expected to be sourced successfully.
+	files, err := filepath.Glob("fixtures/shell/*.env")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("No shell fixtures found: %v.", err)
+	}
+
+	lookup := func(key string) (string, bool) {
+		value, ok := shellEnviron[key]
+		return value, ok
+	}
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("Error reading file: %v.", err)
+			}
+
+			shellEnv, shellErr := sourceWithShell(file)
+			if shellErr != nil && !strings.HasPrefix(filepath.Base(file), "invalid") {
+				t.Fatalf("The shell failed on a valid fixture: %v.", shellErr)
+			}
+			envMap, err := parseShell(string(data), lookup)
+			switch {
+			case shellErr != nil && err == nil:
+				t.Fatalf("Expected error, as the shell failed with %q, got %+v.", shellErr, envMap)
+			case shellErr == nil && err != nil:
+				t.Fatalf("Error: %v, but the shell succeeded with %+v.", err, shellEnv)
+			case shellErr != nil:
+				return
+			}
+
+			// The shell environment also has the inherited variables, which are expected only if they were exported.
+			expected := make(map[string]string)
+			for key, value := range shellEnv {
+				inherited, ok := shellEnviron[key]
+				_, exported := envMap[key]
+				if exported || !shellManagedVariables[key] && (!ok || inherited != value) {
+					expected[key] = value
+				}
+			}
+			compareEnvMaps(t, expected, envMap)
+		})
+	}
+}
+
+func TestParseShellErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		line  string
+	}{
+		{"command substitution", "A=1\nB=$(date)", "line 2"},
+		{"backticks", "A=`date`", "line 1"},
+		{"arithmetic expansion", "A=$((1 + 2))", "line 1"},
+		{"special parameter", "A=$1", "line 1"},
+		{"pattern removal", "A=${HOME%/*}", "line 1"},
+		{"tilde of another user", "A=~root", "line 1"},
+		{"pipe", "A=1 | cat", "line 1"},
+		{"unterminated multi-line quote", "A=1\nB=\"x\ny", "line 2"},
+		{"missing closing brace", "A=${B:-x", "line 1"},
+		{"required variable", "A=\n\nB=${A:?must be set}", "line 3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseShell(tt.input, func(string) (string, bool) { return "", false })
+			if err == nil {
+				t.Fatal("Expected error, got nil.")
+			}
+			if !strings.HasPrefix(err.Error(), tt.line+":") {
+				t.Errorf("Expected error at %s, got %q.", tt.line, err)
+			}
+		})
+	}
+}
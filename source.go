@@ -0,0 +1,238 @@
+package godotenv
+
+import (
+	"context"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+)
+
+// Entry is a single environment variable provided by a Source.
+type Entry struct {
+	Key string
+	// Value is the value of the variable, unquoted and with variables expanded.
+	Value string
+
+	// The fields below are set by Scanner only.
+
+	// RawValue is the value as it is written in the file, including quotes.
+	RawValue string
+	// Line is the number of the line the entry is on, starting at 1.
+	Line int
+	// Comment is the inline comment after the value, without the hash.
+	Comment string
+}
+
+// Source provides environment variables.
+//
+// If a source returns several entries with the same key, the last one is used.
+// If a source fails, entries returned along with the error are still used.
+type Source interface {
+	Load(ctx context.Context) ([]Entry, error)
+}
+
+// Order specifies the sources of variables explicitly, from the highest precedence to the lowest.
+//
+// This option replaces the default order (dotenv files first, then system environment), so From..., and PrioritizeSystem
+// options are ignored when it is used.
+//
+//		godotenv.Get(Order(secrets, godotenv.Files(".env"), godotenv.System()))
+func Order(sources ...Source) Option {
+	return func(cfg *config) {
+		cfg.order = sources
+	}
+}
+
+// Files returns a Source that reads the given dotenv files.
+//
+// Variables from later files override variables from earlier ones.
+func Files(filePaths ...string) Source {
+	sources := make(sourceList, 0, len(filePaths))
+	for _, filePath := range filePaths {
+		sources = append(sources, fileSource(filePath))
+	}
+	return sources
+}
+
+// Reader returns a Source that reads dotenv content from r.
+//
+// The name is used in error messages instead of a file name, and its extension defines the format of the content.
+// The reader is consumed by the first Load call.
+func Reader(name string, r io.Reader) Source {
+	return readerSource{name: name, r: r}
+}
+
+// FS returns a Source that reads dotenv files of fsys matching the given patterns.
+//
+// Patterns are matched with fs.Glob. A pattern that matches nothing is opened as is, so a missing file is reported as an error.
+func FS(fsys fs.FS, patterns ...string) Source {
+	sources := make(sourceList, 0, len(patterns))
+	for _, pattern := range patterns {
+		sources = append(sources, fsSource{fsys: fsys, pattern: pattern})
+	}
+	return sources
+}
+
+// System returns a Source that reads the system environment.
+func System() Source {
+	return systemSource{}
+}
+
+// Map returns a Source of the given variables. The map is copied.
+func Map(variables map[string]string) Source {
+	m := make(mapSource, len(variables))
+	for k, v := range variables {
+		m[k] = v
+	}
+	return m
+}
+
+// contentSource is a Source of content which is parsed according to the options of the Get... call.
+type contentSource interface {
+	loadContent(ctx context.Context, p parseConfig) ([]Entry, error)
+}
+
+// loadSource loads src, passing the parsing settings to it, if it accepts them.
+func loadSource(ctx context.Context, src Source, p parseConfig) ([]Entry, error) {
+	if cs, ok := src.(contentSource); ok {
+		return cs.loadContent(ctx, p)
+	}
+	return src.Load(ctx)
+}
+
+// sourceList combines several sources into one; entries of later sources override entries of earlier ones.
+type sourceList []Source
+
+func (l sourceList) Load(ctx context.Context) ([]Entry, error) {
+	return l.loadContent(ctx, defaultParseConfig)
+}
+
+func (l sourceList) loadContent(ctx context.Context, p parseConfig) ([]Entry, error) {
+	var entries []Entry
+
+	for _, src := range l {
+		individualEntries, err := loadSource(ctx, src, p)
+		// Entries read before an error are kept, as with ContinueOnError only invalid ones are skipped.
+		entries = append(entries, individualEntries...)
+		if err != nil {
+			return entries, err
+		}
+	}
+
+	return entries, nil
+}
+
+type fileSource string
+
+func (s fileSource) Load(ctx context.Context) ([]Entry, error) {
+	return s.loadContent(ctx, defaultParseConfig)
+}
+
+func (s fileSource) loadContent(ctx context.Context, p parseConfig) ([]Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(string(s))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return p.read(string(s), formatOf(string(s)), file)
+}
+
+type readerSource struct {
+	name string
+	r    io.Reader
+}
+
+func (s readerSource) Load(ctx context.Context) ([]Entry, error) {
+	return s.loadContent(ctx, defaultParseConfig)
+}
+
+func (s readerSource) loadContent(ctx context.Context, p parseConfig) ([]Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return p.read(s.name, formatOf(s.name), s.r)
+}
+
+type fsSource struct {
+	fsys    fs.FS
+	pattern string
+}
+
+func (s fsSource) Load(ctx context.Context) ([]Entry, error) {
+	return s.loadContent(ctx, defaultParseConfig)
+}
+
+func (s fsSource) loadContent(ctx context.Context, p parseConfig) ([]Entry, error) {
+	filenames, err := fs.Glob(s.fsys, s.pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(filenames) == 0 {
+		filenames = []string{s.pattern}
+	}
+
+	var entries []Entry
+	for _, filename := range filenames {
+		if err := ctx.Err(); err != nil {
+			return entries, err
+		}
+
+		individualEntries, err := readFSFile(s.fsys, filename, p)
+		entries = append(entries, individualEntries...)
+		if err != nil {
+			return entries, err
+		}
+	}
+
+	return entries, nil
+}
+
+func readFSFile(fsys fs.FS, filename string, p parseConfig) ([]Entry, error) {
+	file, err := fsys.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return p.read(filename, formatOf(filename), file)
+}
+
+type systemSource struct{}
+
+func (s systemSource) Load(ctx context.Context) ([]Entry, error) {
+	return s.loadContent(ctx, defaultParseConfig)
+}
+
+// loadContent lists variables of the environment given with WithEnviron or WithLookup options.
+// If they can't be listed, they are looked up when needed instead.
+func (systemSource) loadContent(_ context.Context, p parseConfig) ([]Entry, error) {
+	if p.env.environ == nil {
+		return nil, nil
+	}
+	return entriesOf(environVariables(p.env.environ())), nil
+}
+
+type mapSource map[string]string
+
+func (m mapSource) Load(context.Context) ([]Entry, error) {
+	return entriesOf(m), nil
+}
+
+// entriesOf converts envMap to a list of entries sorted by key.
+func entriesOf(envMap map[string]string) []Entry {
+	entries := make([]Entry, 0, len(envMap))
+	for k, v := range envMap {
+		entries = append(entries, Entry{Key: k, Value: v})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Key < entries[j].Key
+	})
+	return entries
+}
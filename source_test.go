@@ -0,0 +1,101 @@
+package godotenv
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+type fakeSource struct {
+	entries []Entry
+	err     error
+}
+
+func (s fakeSource) Load(context.Context) ([]Entry, error) {
+	return s.entries, s.err
+}
+
+func TestOrder(t *testing.T) {
+	high := fakeSource{entries: []Entry{{Key: "OPTION_A", Value: "high"}}}
+	low := fakeSource{entries: []Entry{{Key: "OPTION_A", Value: "low"}, {Key: "OPTION_B", Value: "low"}}}
+
+	envMap, notFoundVars := Get(Variables("OPTION_A", "OPTION_B", "OPTION_C"), Order(high, low))
+	if len(notFoundVars) != 1 || notFoundVars[0] != "OPTION_C" {
+		t.Errorf("Expected only OPTION_C to not be found, got %+v.", notFoundVars)
+	}
+
+	if envMap["OPTION_A"] != "high" || envMap["OPTION_B"] != "low" {
+		t.Errorf("Sources were not ordered by precedence: %+v.", envMap)
+	}
+
+	envMap, _ = Get(Order(low, high))
+	if len(envMap) != 2 || envMap["OPTION_A"] != "low" || envMap["OPTION_B"] != "low" {
+		t.Errorf("Sources were not ordered by precedence: %+v.", envMap)
+	}
+}
+
+func TestOrderLastEntryWins(t *testing.T) {
+	src := fakeSource{entries: []Entry{{Key: "OPTION_A", Value: "1"}, {Key: "OPTION_A", Value: "2"}}}
+
+	envMap, _ := Get(Variables("OPTION_A"), Order(src))
+	if envMap["OPTION_A"] != "2" {
+		t.Errorf("Expected the last entry to win, got %+v.", envMap)
+	}
+}
+
+func TestOrderWithBuiltInSources(t *testing.T) {
+	err := os.Setenv("OPTION_A", "999")
+	if err != nil {
+		t.Error("Unable to set env variables for test.")
+	}
+	defer os.Setenv("OPTION_A", "")
+
+	secrets := fakeSource{entries: []Entry{{Key: "OPTION_B", Value: "secret"}}}
+
+	envMap, notFoundVars := Get(
+		Variables("OPTION_A", "OPTION_B", "OPTION_C"),
+		Order(secrets, System(), Files("fixtures/plain.env")),
+	)
+	if len(notFoundVars) != 0 {
+		t.Errorf("Some of the variables were not found: %+v.", notFoundVars)
+	}
+
+	expectedValues := map[string]string{
+		"OPTION_A": "999",
+		"OPTION_B": "secret",
+		"OPTION_C": "3",
+	}
+	for key, value := range expectedValues {
+		if envMap[key] != value {
+			t.Errorf("Read got one of the keys wrong: '%s' should be '%s', not '%s'.", key, value, envMap[key])
+		}
+	}
+}
+
+func TestOrderFailingSource(t *testing.T) {
+	sourceErr := errors.New("sidecar is unavailable")
+	broken := fakeSource{entries: []Entry{{Key: "OPTION_A", Value: "partial"}}, err: sourceErr}
+
+	envMap, notFoundVars, err := Load(Variables("OPTION_A", "OPTION_B"), Order(Reader("inline", strings.NewReader("OPTION_B=2")), broken))
+	if !errors.Is(err, sourceErr) {
+		t.Errorf("Expected %v, got %v.", sourceErr, err)
+	}
+	if len(notFoundVars) != 0 {
+		t.Errorf("Some of the variables were not found: %+v.", notFoundVars)
+	}
+	if envMap["OPTION_A"] != "partial" || envMap["OPTION_B"] != "2" {
+		t.Errorf("Read got the values wrong: %+v.", envMap)
+	}
+}
+
+func TestLoadContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, _, err := LoadContext(ctx, From("fixtures/plain.env"))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected %v, got %v.", context.Canceled, err)
+	}
+}
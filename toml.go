@@ -0,0 +1,519 @@
+package godotenv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+func parseTOML(r io.Reader, p parseConfig) (map[string]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	parser := tomlParser{input: string(data)}
+	document, err := parser.parse()
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", parser.line(), err)
+	}
+
+	envMap := make(map[string]string)
+	if err := p.flatten(envMap, "", document); err != nil {
+		return nil, err
+	}
+	return envMap, nil
+}
+
+// tomlParser reads a TOML document into nested maps.
+//
+// Values of all types except strings are kept as they are written (without digit separators),
+// as they end up in environment variables anyway.
+type tomlParser struct {
+	input string
+	pos   int
+	// defined holds the paths of tables defined with headers, joined with zero bytes.
+	defined map[string]bool
+}
+
+func (p *tomlParser) line() int {
+	return strings.Count(p.input[:p.pos], "\n") + 1
+}
+
+func (p *tomlParser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *tomlParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *tomlParser) consume(s string) bool {
+	if strings.HasPrefix(p.input[p.pos:], s) {
+		p.pos += len(s)
+		return true
+	}
+	return false
+}
+
+func (p *tomlParser) skipSpaces() {
+	for !p.eof() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+// skipBlank skips whitespace, comments and line breaks.
+func (p *tomlParser) skipBlank() {
+	for !p.eof() {
+		switch p.peek() {
+		case ' ', '\t', '\r', '\n':
+			p.pos++
+		case '#':
+			p.skipComment()
+		default:
+			return
+		}
+	}
+}
+
+func (p *tomlParser) skipComment() {
+	for !p.eof() && p.peek() != '\n' {
+		p.pos++
+	}
+}
+
+// endLine expects nothing but an optional comment until the end of the line.
+func (p *tomlParser) endLine() error {
+	p.skipSpaces()
+	if p.peek() == '#' {
+		p.skipComment()
+	}
+	if p.eof() || p.consume("\n") || p.consume("\r\n") {
+		return nil
+	}
+	return fmt.Errorf("unexpected %q after value", p.peek())
+}
+
+func (p *tomlParser) parse() (map[string]interface{}, error) {
+	root := make(map[string]interface{})
+	current := root
+
+	for p.skipBlank(); !p.eof(); p.skipBlank() {
+		var err error
+		switch {
+		case p.consume("[["):
+			current, err = p.parseArrayTable(root)
+		case p.consume("["):
+			current, err = p.parseTable(root)
+		default:
+			err = p.parseKeyValue(current)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err := p.endLine(); err != nil {
+			return nil, err
+		}
+	}
+
+	return root, nil
+}
+
+func (p *tomlParser) parseTable(root map[string]interface{}) (map[string]interface{}, error) {
+	keys, err := p.parseKey()
+	if err != nil {
+		return nil, err
+	}
+	if !p.consume("]") {
+		return nil, errors.New("table header is not closed")
+	}
+
+	path := strings.Join(keys, "\x00")
+	if p.defined[path] {
+		return nil, fmt.Errorf("table %q is already defined", strings.Join(keys, "."))
+	}
+	if p.defined == nil {
+		p.defined = make(map[string]bool)
+	}
+	p.defined[path] = true
+
+	return tomlTable(root, keys)
+}
+
+func (p *tomlParser) parseArrayTable(root map[string]interface{}) (map[string]interface{}, error) {
+	keys, err := p.parseKey()
+	if err != nil {
+		return nil, err
+	}
+	if !p.consume("]]") {
+		return nil, errors.New("array of tables header is not closed")
+	}
+
+	// Tables under the new element of the array are defined anew.
+	path := strings.Join(keys, "\x00")
+	for defined := range p.defined {
+		if strings.HasPrefix(defined, path+"\x00") {
+			delete(p.defined, defined)
+		}
+	}
+
+	parent, err := tomlTable(root, keys[:len(keys)-1])
+	if err != nil {
+		return nil, err
+	}
+
+	last := keys[len(keys)-1]
+	var list []interface{}
+	if existing, ok := parent[last]; ok {
+		if list, ok = existing.([]interface{}); !ok {
+			return nil, fmt.Errorf("key %q is already defined", last)
+		}
+	}
+
+	table := make(map[string]interface{})
+	parent[last] = append(list, table)
+	return table, nil
+}
+
+// tomlTable returns the table at the path, creating missing tables.
+// If the path leads to an array of tables, its last table is used.
+func tomlTable(table map[string]interface{}, keys []string) (map[string]interface{}, error) {
+	for _, key := range keys {
+		switch child := table[key].(type) {
+		case nil:
+			next := make(map[string]interface{})
+			table[key] = next
+			table = next
+		case map[string]interface{}:
+			table = child
+		case []interface{}:
+			if len(child) == 0 {
+				return nil, fmt.Errorf("key %q is not a table", key)
+			}
+			last, ok := child[len(child)-1].(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("key %q is not a table", key)
+			}
+			table = last
+		default:
+			return nil, fmt.Errorf("key %q is not a table", key)
+		}
+	}
+	return table, nil
+}
+
+func (p *tomlParser) parseKeyValue(table map[string]interface{}) error {
+	keys, err := p.parseKey()
+	if err != nil {
+		return err
+	}
+	if !p.consume("=") {
+		return errors.New("can't separate key from value")
+	}
+	p.skipSpaces()
+
+	value, err := p.parseValue()
+	if err != nil {
+		return err
+	}
+
+	parent, err := tomlTable(table, keys[:len(keys)-1])
+	if err != nil {
+		return err
+	}
+
+	last := keys[len(keys)-1]
+	if _, ok := parent[last]; ok {
+		return fmt.Errorf("key %q is already defined", last)
+	}
+	parent[last] = value
+	return nil
+}
+
+// parseKey parses a dotted key and the spaces around it.
+func (p *tomlParser) parseKey() ([]string, error) {
+	var keys []string
+
+	for {
+		p.skipSpaces()
+
+		var key string
+		var err error
+		switch c := p.peek(); {
+		case c == '"':
+			p.pos++
+			key, err = p.parseBasicString()
+		case c == '\'':
+			p.pos++
+			key, err = p.parseLiteralString()
+		default:
+			start := p.pos
+			for !p.eof() && isBareKeyChar(p.peek()) {
+				p.pos++
+			}
+			if start == p.pos {
+				return nil, errors.New("key is missing")
+			}
+			key = p.input[start:p.pos]
+		}
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+
+		p.skipSpaces()
+		if !p.consume(".") {
+			return keys, nil
+		}
+	}
+}
+
+func isBareKeyChar(c byte) bool {
+	return c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '_' || c == '-'
+}
+
+func (p *tomlParser) parseValue() (interface{}, error) {
+	switch {
+	case p.consume(`"""`):
+		return p.parseMultilineBasicString()
+	case p.consume(`"`):
+		return p.parseBasicString()
+	case p.consume(`'''`):
+		return p.parseMultilineLiteralString()
+	case p.consume(`'`):
+		return p.parseLiteralString()
+	case p.consume("["):
+		return p.parseArray()
+	case p.consume("{"):
+		return p.parseInlineTable()
+	default:
+		return p.parseScalar()
+	}
+}
+
+func (p *tomlParser) parseBasicString() (string, error) {
+	var sb strings.Builder
+	for !p.eof() {
+		c := p.input[p.pos]
+		switch c {
+		case '"':
+			p.pos++
+			return sb.String(), nil
+		case '\n':
+			return "", errors.New("string is not closed")
+		case '\\':
+			if err := p.parseEscape(&sb); err != nil {
+				return "", err
+			}
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+	return "", errors.New("string is not closed")
+}
+
+func (p *tomlParser) parseMultilineBasicString() (string, error) {
+	// A line break right after the opening delimiter is trimmed.
+	p.consume("\r")
+	p.consume("\n")
+
+	var sb strings.Builder
+	for !p.eof() {
+		if p.consume(`"""`) {
+			// Up to two quotes right before the closing delimiter belong to the string.
+			for i := 0; i < 2 && p.consume(`"`); i++ {
+				sb.WriteByte('"')
+			}
+			return sb.String(), nil
+		}
+
+		c := p.input[p.pos]
+		if c != '\\' {
+			sb.WriteByte(c)
+			p.pos++
+			continue
+		}
+
+		// A backslash at the end of a line trims all whitespace up to the next non-whitespace character.
+		rest := strings.TrimLeft(p.input[p.pos+1:], " \t")
+		if strings.HasPrefix(rest, "\n") || strings.HasPrefix(rest, "\r\n") {
+			p.pos = len(p.input) - len(strings.TrimLeft(rest, " \t\r\n"))
+			continue
+		}
+
+		if err := p.parseEscape(&sb); err != nil {
+			return "", err
+		}
+	}
+	return "", errors.New("string is not closed")
+}
+
+func (p *tomlParser) parseEscape(sb *strings.Builder) error {
+	p.pos++ // The backslash.
+	if p.eof() {
+		return errors.New("string is not closed")
+	}
+
+	c := p.input[p.pos]
+	p.pos++
+	switch c {
+	case 'b':
+		sb.WriteByte('\b')
+	case 't':
+		sb.WriteByte('\t')
+	case 'n':
+		sb.WriteByte('\n')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'r':
+		sb.WriteByte('\r')
+	case '"', '\\':
+		sb.WriteByte(c)
+	case 'u', 'U':
+		size := 4
+		if c == 'U' {
+			size = 8
+		}
+		if p.pos+size > len(p.input) {
+			return errors.New("invalid unicode escape")
+		}
+		code, err := strconv.ParseUint(p.input[p.pos:p.pos+size], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return errors.New("invalid unicode escape")
+		}
+		sb.WriteRune(rune(code))
+		p.pos += size
+	default:
+		return fmt.Errorf("invalid escape sequence \\%c", c)
+	}
+	return nil
+}
+
+func (p *tomlParser) parseLiteralString() (string, error) {
+	end := strings.IndexAny(p.input[p.pos:], "'\n")
+	if end < 0 || p.input[p.pos+end] != '\'' {
+		return "", errors.New("string is not closed")
+	}
+	s := p.input[p.pos : p.pos+end]
+	p.pos += end + 1
+	return s, nil
+}
+
+func (p *tomlParser) parseMultilineLiteralString() (string, error) {
+	p.consume("\r")
+	p.consume("\n")
+
+	end := strings.Index(p.input[p.pos:], "'''")
+	if end < 0 {
+		return "", errors.New("string is not closed")
+	}
+	s := p.input[p.pos : p.pos+end]
+	p.pos += end + 3
+	for i := 0; i < 2 && p.consume("'"); i++ {
+		s += "'"
+	}
+	return s, nil
+}
+
+func (p *tomlParser) parseArray() (interface{}, error) {
+	list := make([]interface{}, 0)
+	for {
+		p.skipBlank()
+		if p.consume("]") {
+			return list, nil
+		}
+
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+
+		p.skipBlank()
+		if p.consume("]") {
+			return list, nil
+		}
+		if !p.consume(",") {
+			return nil, errors.New("array elements must be separated by commas")
+		}
+	}
+}
+
+func (p *tomlParser) parseInlineTable() (interface{}, error) {
+	table := make(map[string]interface{})
+
+	p.skipSpaces()
+	if p.consume("}") {
+		return table, nil
+	}
+
+	for {
+		if err := p.parseKeyValue(table); err != nil {
+			return nil, err
+		}
+
+		p.skipSpaces()
+		if p.consume("}") {
+			return table, nil
+		}
+		if !p.consume(",") {
+			return nil, errors.New("inline table elements must be separated by commas")
+		}
+	}
+}
+
+// isTOMLDateTimeSpace reports whether the space at the position separates a date from a time, like in 1979-05-27 07:32:00.
+func isTOMLDateTimeSpace(value string, space int) bool {
+	date := value[:space]
+	if len(date) != len("1979-05-27") || date[4] != '-' || date[7] != '-' {
+		return false
+	}
+	for i, c := range date {
+		if i != 4 && i != 7 && (c < '0' || c > '9') {
+			return false
+		}
+	}
+	return space+1 < len(value) && value[space+1] >= '0' && value[space+1] <= '9'
+}
+
+// parseScalar parses booleans, numbers and dates.
+func (p *tomlParser) parseScalar() (interface{}, error) {
+	start := p.pos
+	for !p.eof() && !strings.ContainsRune(",]}#\r\n", rune(p.peek())) {
+		p.pos++
+	}
+	// Trailing spaces are not part of the value, but a space between a date and a time is.
+	for p.pos > start && (p.input[p.pos-1] == ' ' || p.input[p.pos-1] == '\t') {
+		p.pos--
+	}
+
+	value := p.input[start:p.pos]
+	if value == "" {
+		return nil, errors.New("value is missing")
+	}
+	if value == "true" || value == "false" {
+		return value, nil
+	}
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if !isBareKeyChar(c) && !strings.ContainsRune("+.:", rune(c)) && !(c == ' ' && isTOMLDateTimeSpace(value, i)) {
+			return nil, fmt.Errorf("invalid value %q", value)
+		}
+	}
+	if c := value[0]; c != '+' && c != '-' && (c < '0' || c > '9') && value != "inf" && value != "nan" {
+		return nil, fmt.Errorf("invalid value %q", value)
+	}
+
+	if !strings.ContainsAny(value, ":") {
+		value = strings.ReplaceAll(value, "_", "")
+	}
+	return value, nil
+}
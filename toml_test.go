@@ -0,0 +1,88 @@
+package godotenv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadTOML(t *testing.T) {
+	envMap, err := read([]Source{Files("fixtures/config.toml")})
+	if err != nil {
+		t.Fatalf("Error reading file: %v.", err)
+	}
+
+	compareEnvMaps(t, map[string]string{
+		"OPTION_A":           "toml",
+		"OPTION_B":           "1000",
+		"hosts":              "a,b",
+		"db_host":            "localhost",
+		"db_port":            "5432",
+		"db_options_sslmode": "disable",
+		"servers_0_name":     "x",
+		"servers_1_name":     "y",
+	}, envMap)
+}
+
+func TestParseTOMLValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected map[string]string
+	}{
+		{"basic string escapes", `a = "tab\there \"q\" \u00e9"`, map[string]string{"a": "tab\there \"q\" é"}},
+		{"literal string", `a = 'C:\path'`, map[string]string{"a": `C:\path`}},
+		{"multiline basic string", "a = \"\"\"\nline1\nline2 \\\n   continued\"\"\"", map[string]string{"a": "line1\nline2 continued"}},
+		{"multiline literal string", "a = '''\nraw\\n\n'''", map[string]string{"a": "raw\\n\n"}},
+		{"quoted and dotted keys", `"x.y".z = 1` + "\n" + `site."google.com" = true`, map[string]string{"x.y_z": "1", "site_google.com": "true"}},
+		{"dates", "a = 1979-05-27 07:32:00Z # comment\nb = 07:32:00", map[string]string{"a": "1979-05-27 07:32:00Z", "b": "07:32:00"}},
+		{"numbers", "a = -3.14\nb = +inf\nc = 0xDEAD_BEEF", map[string]string{"a": "-3.14", "b": "+inf", "c": "0xDEADBEEF"}},
+		{"nested arrays", "a = [[1, 2], ['x']]", map[string]string{"a_0": "1,2", "a_1": "x"}},
+		{"empty inline table", "a = {}\nb = 1", map[string]string{"b": "1"}},
+		{"sub-table of array of tables", "[[a]]\nx = 1\n[a.b]\ny = 2", map[string]string{"a_0_x": "1", "a_0_b_y": "2"}},
+		{"sub-tables of several tables of an array", "[[a]]\n[a.b]\ny = 1\n[[a]]\n[a.b]\ny = 2", map[string]string{"a_0_b_y": "1", "a_1_b_y": "2"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envMap, err := parseTOML(strings.NewReader(tt.input), defaultParseConfig)
+			if err != nil {
+				t.Fatalf("Error: %v.", err)
+			}
+			compareEnvMaps(t, tt.expected, envMap)
+		})
+	}
+}
+
+func TestParseTOMLErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		line  string
+	}{
+		{"missing value", "a = 1\nb =", "line 2"},
+		{"duplicate key", "a = 1\n\na = 2", "line 3"},
+		{"unclosed string", "a = \"x", "line 1"},
+		{"unclosed header", "[a\nb = 1", "line 1"},
+		{"garbage after value", "a = 'x' y", "line 1"},
+		{"invalid escape", `a = "\q"`, "line 1"},
+		{"invalid bare value", "a = hello", "line 1"},
+		{"table over value", "a = 1\n[a]", "line 2"},
+		{"table under empty array", "a = []\n[a.b]", "line 2"},
+		{"dotted key under empty array", "a = []\na.b = 1", "line 2"},
+		{"space in value", "a = 1 2", "line 1"},
+		{"space after date", "a = 1979-05-27 x", "line 1"},
+		{"duplicate table", "[a]\nb = 1\n[c]\n[a]\nd = 2", "line 4"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseTOML(strings.NewReader(tt.input), defaultParseConfig)
+			if err == nil {
+				t.Fatal("Expected error, got nil.")
+			}
+			if !strings.HasPrefix(err.Error(), tt.line+":") {
+				t.Errorf("Expected error at %s, got %q.", tt.line, err)
+			}
+		})
+	}
+}
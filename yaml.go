@@ -0,0 +1,570 @@
+package godotenv
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+func parseYAML(r io.Reader, p parseConfig) (map[string]string, error) {
+	var lines []string
+	reader := &lineReader{r: bufio.NewReader(r), maxLineSize: p.maxLineSize}
+	for {
+		line, err := reader.readLine()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, lineError(len(lines)+1, keyBefore(line, ":"), err)
+		}
+		lines = append(lines, string(line))
+	}
+
+	parser := yamlParser{lines: lines}
+	document, err := parser.parseDocument()
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", parser.lineNumber(), err)
+	}
+
+	envMap := make(map[string]string)
+	if err := p.flatten(envMap, "", document); err != nil {
+		return nil, err
+	}
+	return envMap, nil
+}
+
+// yamlParser reads a YAML document into nested maps and lists.
+//
+// It supports block and flow collections, quoted and plain scalars and block scalars. Anchors, aliases, tags,
+// complex keys and multi-line flow collections or quoted scalars are not supported. All scalars are kept as they
+// are written, except null, which becomes an empty value.
+type yamlParser struct {
+	lines []string
+	pos   int
+}
+
+func (p *yamlParser) lineNumber() int {
+	if p.pos >= len(p.lines) {
+		return len(p.lines)
+	}
+	return p.pos + 1
+}
+
+// next skips blank lines, comments and document markers, and returns the indentation and content of the next line.
+func (p *yamlParser) next() (indent int, content string, ok bool, err error) {
+	for ; p.pos < len(p.lines); p.pos++ {
+		line := p.lines[p.pos]
+		content = strings.TrimLeft(line, " ")
+		indent = len(line) - len(content)
+
+		trimmed := strings.TrimSpace(content)
+		if trimmed == "" || trimmed[0] == '#' {
+			continue
+		}
+		if indent == 0 && (trimmed == "---" || strings.HasPrefix(trimmed, "--- ") || trimmed == "..." || trimmed[0] == '%') {
+			continue
+		}
+		if content[0] == '\t' {
+			return 0, "", false, errors.New("tabs can't be used for indentation")
+		}
+
+		return indent, strings.TrimRight(content, " \t"), true, nil
+	}
+	return 0, "", false, nil
+}
+
+func (p *yamlParser) parseDocument() (map[string]interface{}, error) {
+	indent, content, ok, err := p.next()
+	if err != nil || !ok {
+		return map[string]interface{}{}, err
+	}
+	if _, _, isKey, err := splitYAMLKey(content); err != nil || !isKey {
+		if err == nil {
+			err = errors.New("top-level value is not a mapping")
+		}
+		return nil, err
+	}
+
+	document, err := p.parseMapping(indent)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, _, ok, err := p.next(); err != nil || ok {
+		if err == nil {
+			err = errors.New("unexpected indentation")
+		}
+		return nil, err
+	}
+	return document, nil
+}
+
+// parseBlock parses a collection or a scalar that starts on the next line with the indentation greater than parent.
+// A sequence of a mapping's value can also have the same indentation as the mapping's keys.
+func (p *yamlParser) parseBlock(parent int, sameIndentSequence bool) (interface{}, error) {
+	indent, content, ok, err := p.next()
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	switch {
+	case isYAMLSequenceItem(content) && (indent > parent || indent == parent && sameIndentSequence):
+		return p.parseSequence(indent)
+	case indent <= parent:
+		return nil, nil
+	}
+
+	if _, _, isKey, err := splitYAMLKey(content); err != nil {
+		return nil, err
+	} else if isKey {
+		return p.parseMapping(indent)
+	}
+
+	return p.parseValue(parent, content)
+}
+
+func (p *yamlParser) parseMapping(indent int) (map[string]interface{}, error) {
+	mapping := make(map[string]interface{})
+
+	for {
+		lineIndent, content, ok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok || lineIndent < indent {
+			return mapping, nil
+		}
+		if lineIndent > indent {
+			return nil, errors.New("unexpected indentation")
+		}
+
+		key, rest, isKey, err := splitYAMLKey(content)
+		if err != nil {
+			return nil, err
+		}
+		if !isKey {
+			return nil, errors.New("expected a key")
+		}
+		if _, ok := mapping[key]; ok {
+			return nil, fmt.Errorf("key %q is already defined", key)
+		}
+
+		var value interface{}
+		if rest == "" || rest[0] == '#' {
+			p.pos++
+			value, err = p.parseBlock(indent, true)
+		} else {
+			value, err = p.parseValue(indent, rest)
+		}
+		if err != nil {
+			return nil, err
+		}
+		mapping[key] = value
+	}
+}
+
+func (p *yamlParser) parseSequence(indent int) ([]interface{}, error) {
+	sequence := make([]interface{}, 0)
+
+	for {
+		lineIndent, content, ok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok || lineIndent < indent || lineIndent == indent && !isYAMLSequenceItem(content) {
+			return sequence, nil
+		}
+		if lineIndent > indent {
+			return nil, errors.New("unexpected indentation")
+		}
+
+		item := strings.TrimLeft(content[1:], " ")
+		if item == "" || item[0] == '#' {
+			p.pos++
+			value, err := p.parseBlock(indent, false)
+			if err != nil {
+				return nil, err
+			}
+			sequence = append(sequence, value)
+			continue
+		}
+
+		// The item is parsed as if the dash were a part of its indentation, so "- key: value" starts a mapping.
+		itemIndent := indent + len(content) - len(item)
+		p.lines[p.pos] = strings.Repeat(" ", itemIndent) + item
+
+		_, _, isKey, err := splitYAMLKey(item)
+		if err != nil {
+			return nil, err
+		}
+
+		var value interface{}
+		switch {
+		case isKey:
+			value, err = p.parseMapping(itemIndent)
+		case isYAMLSequenceItem(item):
+			value, err = p.parseSequence(itemIndent)
+		default:
+			value, err = p.parseValue(indent, item)
+		}
+		if err != nil {
+			return nil, err
+		}
+		sequence = append(sequence, value)
+	}
+}
+
+// parseValue parses a value that starts on the current line.
+func (p *yamlParser) parseValue(parent int, value string) (interface{}, error) {
+	switch value[0] {
+	case '|', '>':
+		return p.parseBlockScalar(parent, value)
+	case '&', '*', '!':
+		return nil, errors.New("anchors, aliases and tags are not supported")
+	case '[', '{', '"', '\'':
+		flow := yamlFlowParser{input: value}
+		result, err := flow.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		if rest := strings.TrimSpace(flow.input[flow.pos:]); rest != "" && rest[0] != '#' {
+			return nil, fmt.Errorf("unexpected %q after value", rest)
+		}
+		p.pos++
+		return result, nil
+	}
+
+	// A plain scalar can be continued on the following lines with greater indentation.
+	parts := []string{stripYAMLComment(value)}
+	for p.pos++; ; p.pos++ {
+		indent, content, ok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		if !ok || indent <= parent || strings.Contains(value, " #") {
+			break
+		}
+		if _, _, isKey, _ := splitYAMLKey(content); isKey || isYAMLSequenceItem(content) {
+			return nil, errors.New("unexpected indentation")
+		}
+		parts = append(parts, stripYAMLComment(content))
+		value = content
+	}
+
+	return yamlScalar(strings.Join(parts, " ")), nil
+}
+
+func (p *yamlParser) parseBlockScalar(parent int, header string) (string, error) {
+	literal := header[0] == '|'
+	chomping := byte(0)
+	contentIndent := 0
+
+	for _, c := range []byte(stripYAMLComment(header[1:])) {
+		switch {
+		case (c == '-' || c == '+') && chomping == 0:
+			chomping = c
+		case c >= '1' && c <= '9' && contentIndent == 0:
+			contentIndent = parent + int(c-'0')
+		default:
+			return "", fmt.Errorf("invalid block scalar header %q", header)
+		}
+	}
+
+	var lines []string
+	for p.pos++; p.pos < len(p.lines); p.pos++ {
+		line := p.lines[p.pos]
+		trimmed := strings.TrimLeft(line, " ")
+		indent := len(line) - len(trimmed)
+
+		if strings.TrimSpace(line) == "" {
+			lines = append(lines, "")
+			continue
+		}
+		if contentIndent == 0 {
+			if indent <= parent {
+				break
+			}
+			contentIndent = indent
+		}
+		if indent < contentIndent {
+			break
+		}
+		lines = append(lines, line[contentIndent:])
+	}
+
+	trailing := 0
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+		trailing++
+	}
+
+	var sb strings.Builder
+	for i, line := range lines {
+		if i > 0 {
+			prev := lines[i-1]
+			switch {
+			case literal || line == "" || isMoreIndented(prev) || isMoreIndented(line):
+				sb.WriteByte('\n')
+			case prev == "":
+				// The line break is already written for the empty line.
+			default:
+				sb.WriteByte(' ')
+			}
+		}
+		sb.WriteString(line)
+	}
+
+	if len(lines) > 0 {
+		switch chomping {
+		case '-':
+		case '+':
+			sb.WriteString(strings.Repeat("\n", trailing+1))
+		default:
+			sb.WriteByte('\n')
+		}
+	}
+	return sb.String(), nil
+}
+
+func isMoreIndented(line string) bool {
+	return line != "" && (line[0] == ' ' || line[0] == '\t')
+}
+
+func isYAMLSequenceItem(content string) bool {
+	return content == "-" || strings.HasPrefix(content, "- ")
+}
+
+// splitYAMLKey splits "key: value" content. It reports false if the content is not a mapping entry.
+func splitYAMLKey(content string) (key, rest string, ok bool, err error) {
+	if content[0] == '"' || content[0] == '\'' {
+		flow := yamlFlowParser{input: content}
+		key, err := flow.parseQuoted()
+		if err != nil {
+			return "", "", false, err
+		}
+		rest := strings.TrimLeft(content[flow.pos:], " ")
+		if rest == ":" || strings.HasPrefix(rest, ": ") {
+			return key, strings.TrimLeft(rest[1:], " "), true, nil
+		}
+		return "", "", false, nil
+	}
+
+	if content[0] == '[' || content[0] == '{' || isYAMLSequenceItem(content) {
+		return "", "", false, nil
+	}
+
+	for i := 0; i < len(content); i++ {
+		switch {
+		case content[i] == '#' && i > 0 && content[i-1] == ' ':
+			return "", "", false, nil
+		case content[i] == ':' && (i+1 == len(content) || content[i+1] == ' '):
+			return strings.TrimRight(content[:i], " "), strings.TrimLeft(content[i+1:], " "), true, nil
+		}
+	}
+	return "", "", false, nil
+}
+
+func stripYAMLComment(value string) string {
+	if strings.HasPrefix(value, "#") {
+		return ""
+	}
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = value[:i]
+	}
+	return strings.TrimSpace(value)
+}
+
+// yamlScalar converts a plain scalar; null values become nil.
+func yamlScalar(value string) interface{} {
+	switch value {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	}
+	return value
+}
+
+// yamlFlowParser parses flow collections and quoted scalars within a single line.
+type yamlFlowParser struct {
+	input string
+	pos   int
+}
+
+func (p *yamlFlowParser) skipSpaces() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+func (p *yamlFlowParser) consume(c byte) bool {
+	p.skipSpaces()
+	if p.pos < len(p.input) && p.input[p.pos] == c {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *yamlFlowParser) parseValue() (interface{}, error) {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return nil, errors.New("value is missing")
+	}
+
+	switch p.input[p.pos] {
+	case '[':
+		p.pos++
+		return p.parseSequence()
+	case '{':
+		p.pos++
+		return p.parseMapping()
+	case '"', '\'':
+		return p.parseQuoted()
+	case '&', '*', '!':
+		return nil, errors.New("anchors, aliases and tags are not supported")
+	}
+
+	start := p.pos
+	for p.pos < len(p.input) && !strings.ContainsRune(",[]{}", rune(p.input[p.pos])) {
+		if p.input[p.pos] == ':' && (p.pos+1 == len(p.input) || p.input[p.pos+1] == ' ') {
+			break
+		}
+		p.pos++
+	}
+	return yamlScalar(strings.TrimSpace(p.input[start:p.pos])), nil
+}
+
+func (p *yamlFlowParser) parseSequence() (interface{}, error) {
+	sequence := make([]interface{}, 0)
+	if p.consume(']') {
+		return sequence, nil
+	}
+
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		sequence = append(sequence, value)
+
+		if p.consume(']') {
+			return sequence, nil
+		}
+		if !p.consume(',') {
+			return nil, errors.New("flow sequence is not closed")
+		}
+		if p.consume(']') {
+			return sequence, nil
+		}
+	}
+}
+
+func (p *yamlFlowParser) parseMapping() (interface{}, error) {
+	mapping := make(map[string]interface{})
+	if p.consume('}') {
+		return mapping, nil
+	}
+
+	for {
+		key, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		keyString, ok := key.(string)
+		if !ok {
+			keyString = scalar(key)
+		}
+
+		var value interface{}
+		if p.consume(':') {
+			if value, err = p.parseValue(); err != nil {
+				return nil, err
+			}
+		}
+		mapping[keyString] = value
+
+		if p.consume('}') {
+			return mapping, nil
+		}
+		if !p.consume(',') {
+			return nil, errors.New("flow mapping is not closed")
+		}
+		if p.consume('}') {
+			return mapping, nil
+		}
+	}
+}
+
+func (p *yamlFlowParser) parseQuoted() (string, error) {
+	quote := p.input[p.pos]
+	p.pos++
+
+	var sb strings.Builder
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		p.pos++
+
+		switch {
+		case c == quote && quote == '\'' && p.pos < len(p.input) && p.input[p.pos] == '\'':
+			sb.WriteByte('\'')
+			p.pos++
+		case c == quote:
+			return sb.String(), nil
+		case c == '\\' && quote == '"':
+			if err := p.parseEscape(&sb); err != nil {
+				return "", err
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	return "", errors.New("quoted scalar is not closed")
+}
+
+func (p *yamlFlowParser) parseEscape(sb *strings.Builder) error {
+	if p.pos >= len(p.input) {
+		return errors.New("quoted scalar is not closed")
+	}
+
+	c := p.input[p.pos]
+	p.pos++
+	switch c {
+	case '0':
+		sb.WriteByte(0)
+	case 'a':
+		sb.WriteByte('\a')
+	case 'b':
+		sb.WriteByte('\b')
+	case 't', '\t':
+		sb.WriteByte('\t')
+	case 'n':
+		sb.WriteByte('\n')
+	case 'v':
+		sb.WriteByte('\v')
+	case 'f':
+		sb.WriteByte('\f')
+	case 'r':
+		sb.WriteByte('\r')
+	case 'e':
+		sb.WriteByte(0x1b)
+	case ' ', '"', '/', '\\':
+		sb.WriteByte(c)
+	case 'x', 'u', 'U':
+		size := map[byte]int{'x': 2, 'u': 4, 'U': 8}[c]
+		if p.pos+size > len(p.input) {
+			return errors.New("invalid unicode escape")
+		}
+		code, err := strconv.ParseUint(p.input[p.pos:p.pos+size], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return errors.New("invalid unicode escape")
+		}
+		sb.WriteRune(rune(code))
+		p.pos += size
+	default:
+		return fmt.Errorf("invalid escape sequence \\%c", c)
+	}
+	return nil
+}
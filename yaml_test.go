@@ -0,0 +1,122 @@
+package godotenv
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadYAML(t *testing.T) {
+	envMap, err := read([]Source{Files("fixtures/config.yaml")})
+	if err != nil {
+		t.Fatalf("Error reading file: %v.", err)
+	}
+
+	compareEnvMaps(t, map[string]string{
+		"OPTION_A":       "yaml",
+		"quoted key":     "value",
+		"url":            "http://example.com:8080/path#fragment",
+		"db_host":        "localhost",
+		"db_port":        "5432",
+		"hosts":          "a,b",
+		"servers_0_name": "x",
+		"servers_0_port": "1",
+		"servers_1_name": "y",
+		"servers_1_port": "2",
+		"certificate":    "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n",
+	}, envMap)
+}
+
+func TestParseYAMLValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected map[string]string
+	}{
+		{"yaml style options", "OPTION_A: 1", map[string]string{"OPTION_A": "1"}},
+		{"values with equal signs", "OPTION_A: Foo=bar", map[string]string{"OPTION_A": "Foo=bar"}},
+		{"values with colons", "OPTION_A: 1:B\nOPTION_B: 'a: b'", map[string]string{"OPTION_A": "1:B", "OPTION_B": "a: b"}},
+		{"null values", "a:\nb: ~\nc: null # comment", map[string]string{"a": "", "b": "", "c": ""}},
+		{"double quoted escapes", `a: "tab\there \"q\" \u00e9"`, map[string]string{"a": "tab\there \"q\" é"}},
+		{"single quoted", `a: 'it''s # not a comment'`, map[string]string{"a": "it's # not a comment"}},
+		{"plain multi-line", "a: first\n  second\nb: 1", map[string]string{"a": "first second", "b": "1"}},
+		{"literal keep", "a: |+\n  x\n\n\nb: 1", map[string]string{"a": "x\n\n\n", "b": "1"}},
+		{"literal strip", "a: |-\n  x\n   y\n", map[string]string{"a": "x\n y"}},
+		{"literal indentation indicator", "a: |2\n    x\n  y", map[string]string{"a": "  x\ny\n"}},
+		{"folded", "a: >\n  one\n  two\n\n  three\n    more\n  four\n", map[string]string{"a": "one two\nthree\n  more\nfour\n"}},
+		{"flow collections", "a: [x, 'y, z', {b: 1}]\nc: {d: [1, 2], e: f}", map[string]string{"a_0": "x", "a_1": "y, z", "a_2_b": "1", "c_d": "1,2", "c_e": "f"}},
+		{"nested sequences", "a:\n  - - 1\n    - 2\n  -\n    - 3", map[string]string{"a_0": "1,2", "a_1": "3"}},
+		{"empty sequence item", "a:\n  -\n  - b", map[string]string{"a": ",b"}},
+		{"sequence item with a block scalar", "a:\n  - |\n    x\n  - y", map[string]string{"a": "x\n,y"}},
+		{"comment after key", "a: # comment\n  b: 1", map[string]string{"a_b": "1"}},
+		{"document markers", "%YAML 1.2\n---\na: 1\n...", map[string]string{"a": "1"}},
+		{"empty document", "# nothing here\n", map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			envMap, err := parseYAML(strings.NewReader(tt.input), defaultParseConfig)
+			if err != nil {
+				t.Fatalf("Error: %v.", err)
+			}
+			compareEnvMaps(t, tt.expected, envMap)
+		})
+	}
+}
+
+func TestListSeparator(t *testing.T) {
+	input := "hosts: [a, b]"
+
+	envMap, _ := Get(Variables("hosts"), FromReader("config.yaml", strings.NewReader(input)), ListSeparator(" "))
+	if envMap["hosts"] != "a b" {
+		t.Errorf("Expected list to be joined with spaces, got %+v.", envMap)
+	}
+
+	envMap, _ = Get(Variables("hosts_0", "hosts_1"), FromReader("config.yaml", strings.NewReader(input)), ListSeparator(""))
+	if envMap["hosts_0"] != "a" || envMap["hosts_1"] != "b" {
+		t.Errorf("Expected list elements to be indexed, got %+v.", envMap)
+	}
+}
+
+func TestYAMLFormatOption(t *testing.T) {
+	envMap, notFoundVars := Get(Variables("OPTION_A"), FromReader("inline", strings.NewReader("OPTION_A: 1")), Format(YAML))
+	if len(notFoundVars) != 0 || envMap["OPTION_A"] != "1" {
+		t.Errorf("Expected yaml style line to be parsed, got %+v.", envMap)
+	}
+
+	_, _, err := Load(FromReader("inline", strings.NewReader("OPTION_A: 1")))
+	if err == nil {
+		t.Error("Expected yaml style line to be rejected without YAML format.")
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		line  string
+	}{
+		{"top-level scalar", "just text", "line 1"},
+		{"unexpected indentation", "a: 1\n   b: 2", "line 2"},
+		{"missing key", "a:\n  b: 1\n  text", "line 3"},
+		{"duplicate key", "a: 1\n\na: 2", "line 3"},
+		{"unclosed quote", "a: 1\nb: \"x", "line 2"},
+		{"unclosed flow sequence", "a: [1, 2", "line 1"},
+		{"garbage after quoted value", "a: 'x' y", "line 1"},
+		{"tab indentation", "a:\n\tb: 1", "line 2"},
+		{"alias", "a: &anchor 1\nb: *anchor", "line 1"},
+		{"invalid escape", `a: "\q"`, "line 1"},
+		{"invalid block scalar header", "a: |x\n  y", "line 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseYAML(strings.NewReader(tt.input), defaultParseConfig)
+			if err == nil {
+				t.Fatal("Expected error, got nil.")
+			}
+			if !strings.HasPrefix(err.Error(), tt.line+":") {
+				t.Errorf("Expected error at %s, got %q.", tt.line, err)
+			}
+		})
+	}
+}